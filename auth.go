@@ -0,0 +1,325 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 认证类型
+const (
+	authTypeBasic  = "basic"
+	authTypeBearer = "bearer"
+	authTypeAPIKey = "apikey"
+	authTypeHMAC   = "hmac"
+	authTypeOAuth2 = "oauth2"
+)
+
+// 发送请求的认证配置
+type AuthConfig struct {
+	Type string `json:"type"`
+
+	// Basic
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Bearer
+	Token string `json:"token,omitempty"`
+
+	// API Key，位置为header或query
+	KeyName  string `json:"key_name,omitempty"`
+	KeyValue string `json:"key_value,omitempty"`
+	KeyIn    string `json:"key_in,omitempty"`
+
+	// HMAC签名
+	AppID          string `json:"app_id,omitempty"`
+	Secret         string `json:"secret,omitempty"`
+	Algorithm      string `json:"algorithm,omitempty"`
+	Encoding       string `json:"encoding,omitempty"`
+	SignTemplate   string `json:"sign_template,omitempty"`
+	SignIn         string `json:"sign_in,omitempty"`
+	TimestampUnit  string `json:"timestamp_unit,omitempty"`
+	AppIDField     string `json:"app_id_field,omitempty"`
+	NonceField     string `json:"nonce_field,omitempty"`
+	TimestampField string `json:"timestamp_field,omitempty"`
+	SignField      string `json:"sign_field,omitempty"`
+
+	// OAuth2 client credentials
+	TokenURL     string `json:"token_url,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// 默认签名原文：app_id、nonce、timestamp和原始请求体依次拼接
+const defaultSignTemplate = "{app_id}{nonce}{timestamp}{body}"
+
+// 签名所需的字段值
+type hmacSignature struct {
+	appID     string
+	nonce     string
+	timestamp string
+	sign      string
+}
+
+func (a *AuthConfig) fieldName(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
+
+// 计算签名，body为注入签名字段之前的原始请求体
+func (a *AuthConfig) computeSignature(method, path, body string) (*hmacSignature, error) {
+	nonceBytes := make([]byte, 8)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	if a.TimestampUnit == "s" {
+		timestamp = strconv.FormatInt(now.Unix(), 10)
+	}
+
+	sig := &hmacSignature{
+		appID:     a.AppID,
+		nonce:     hex.EncodeToString(nonceBytes),
+		timestamp: timestamp,
+	}
+
+	template := a.SignTemplate
+	if template == "" {
+		template = defaultSignTemplate
+	}
+	plain := strings.NewReplacer(
+		"{app_id}", sig.appID,
+		"{nonce}", sig.nonce,
+		"{timestamp}", sig.timestamp,
+		"{body}", body,
+		"{method}", method,
+		"{path}", path,
+		"{secret}", a.Secret,
+	).Replace(template)
+
+	var h hash.Hash
+	switch strings.ToLower(a.Algorithm) {
+	case "", "hmac-sha256":
+		h = hmac.New(sha256.New, []byte(a.Secret))
+	case "hmac-sha1":
+		h = hmac.New(sha1.New, []byte(a.Secret))
+	case "hmac-md5":
+		h = hmac.New(md5.New, []byte(a.Secret))
+	case "sha256":
+		h = sha256.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", a.Algorithm)
+	}
+	h.Write([]byte(plain))
+	digest := h.Sum(nil)
+
+	switch strings.ToLower(a.Encoding) {
+	case "", "hex":
+		sig.sign = hex.EncodeToString(digest)
+	case "hex-upper":
+		sig.sign = strings.ToUpper(hex.EncodeToString(digest))
+	case "base64":
+		sig.sign = base64.StdEncoding.EncodeToString(digest)
+	default:
+		return nil, fmt.Errorf("不支持的签名编码: %s", a.Encoding)
+	}
+	return sig, nil
+}
+
+func (a *AuthConfig) signatureFields(sig *hmacSignature) map[string]string {
+	return map[string]string{
+		a.fieldName(a.AppIDField, "app_id"):        sig.appID,
+		a.fieldName(a.NonceField, "nonce"):         sig.nonce,
+		a.fieldName(a.TimestampField, "timestamp"): sig.timestamp,
+		a.fieldName(a.SignField, "sign"):           sig.sign,
+	}
+}
+
+// 在构造请求体之前应用认证，HMAC签名默认写入JSON请求体的顶层字段
+func applyBodyAuth(req *SendRequest) (*hmacSignature, error) {
+	auth := req.Auth
+	if auth == nil || auth.Type != authTypeHMAC {
+		return nil, nil
+	}
+
+	path := ""
+	if u, err := url.Parse(req.URL); err == nil {
+		path = u.Path
+	}
+	sig, err := auth.computeSignature(req.Method, path, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if auth.SignIn != "" && auth.SignIn != "body" {
+		return sig, nil
+	}
+
+	if req.BodyType != "" && req.BodyType != bodyTypeRaw {
+		return nil, errors.New("签名写入请求体仅支持JSON请求体")
+	}
+	body := map[string]interface{}{}
+	if strings.TrimSpace(req.Data) != "" {
+		// 使用Number保留大整数精度
+		decoder := json.NewDecoder(strings.NewReader(req.Data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			return nil, fmt.Errorf("签名写入请求体需要JSON对象: %v", err)
+		}
+	}
+	for k, v := range auth.signatureFields(sig) {
+		body[k] = v
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req.Data = string(data)
+	return sig, nil
+}
+
+// 请求创建后应用认证头或查询参数
+func applyRequestAuth(client *http.Client, httpReq *http.Request, auth *AuthConfig, sig *hmacSignature) error {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "":
+	case authTypeBasic:
+		httpReq.SetBasicAuth(auth.Username, auth.Password)
+	case authTypeBearer:
+		httpReq.Header.Set("Authorization", "Bearer "+auth.Token)
+	case authTypeAPIKey:
+		if auth.KeyName == "" {
+			return errors.New("API Key名称不能为空")
+		}
+		if auth.KeyIn == "query" {
+			query := httpReq.URL.Query()
+			query.Set(auth.KeyName, auth.KeyValue)
+			httpReq.URL.RawQuery = query.Encode()
+		} else {
+			httpReq.Header.Set(auth.KeyName, auth.KeyValue)
+		}
+	case authTypeHMAC:
+		switch auth.SignIn {
+		case "header":
+			for k, v := range auth.signatureFields(sig) {
+				httpReq.Header.Set(k, v)
+			}
+		case "query":
+			query := httpReq.URL.Query()
+			for k, v := range auth.signatureFields(sig) {
+				query.Set(k, v)
+			}
+			httpReq.URL.RawQuery = query.Encode()
+		}
+	case authTypeOAuth2:
+		token, err := fetchOAuth2Token(client, auth)
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	default:
+		return fmt.Errorf("不支持的认证类型: %s", auth.Type)
+	}
+	return nil
+}
+
+type oauth2Token struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+var (
+	oauth2TokensMu sync.Mutex
+	oauth2Tokens   = make(map[string]oauth2Token)
+)
+
+// 通过client credentials方式获取token，过期前一直使用缓存
+func fetchOAuth2Token(client *http.Client, auth *AuthConfig) (string, error) {
+	if auth.TokenURL == "" {
+		return "", errors.New("OAuth2 token地址不能为空")
+	}
+	// 密钥只以哈希形式参与缓存键，修改或吊销密钥后不再使用旧token
+	secret := sha256.Sum256([]byte(auth.ClientSecret))
+	key := strings.Join([]string{auth.TokenURL, auth.ClientID, auth.Scope, hex.EncodeToString(secret[:])}, "|")
+
+	oauth2TokensMu.Lock()
+	defer oauth2TokensMu.Unlock()
+
+	// 提前30秒刷新，避免请求途中过期
+	if token, ok := oauth2Tokens[key]; ok && time.Now().Add(30*time.Second).Before(token.expiresAt) {
+		return token.accessToken, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if auth.Scope != "" {
+		form.Set("scope", auth.Scope)
+	}
+	req, err := http.NewRequest(http.MethodPost, auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(auth.ClientID), url.QueryEscape(auth.ClientSecret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("获取OAuth2 token失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("获取OAuth2 token失败: 状态码 %d, %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析OAuth2 token失败: %v", err)
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("OAuth2响应中没有access_token")
+	}
+
+	// 未返回有效期时按1小时缓存
+	expiresIn, err := result.ExpiresIn.Int64()
+	if err != nil || expiresIn <= 0 {
+		expiresIn = 3600
+	}
+	oauth2Tokens[key] = oauth2Token{
+		accessToken: result.AccessToken,
+		expiresAt:   time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}
+	return result.AccessToken, nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptrace"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+// 原始请求中请求体的最大保留长度
+const maxRawRequestBody = 64 * 1024
+
+// 发送请求各阶段耗时（毫秒），发生重定向时为最后一跳的耗时
+type SendTimings struct {
+	DNSMs      float64 `json:"dns_ms"`
+	ConnectMs  float64 `json:"connect_ms"`
+	TLSMs      float64 `json:"tls_ms"`
+	TTFBMs     float64 `json:"ttfb_ms"`
+	TotalMs    float64 `json:"total_ms"`
+	ConnReused bool    `json:"conn_reused"`
+}
+
+// 重定向链中的一跳
+type RedirectHop struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status"`
+	Location string `json:"location"`
+}
+
+type TLSInfo struct {
+	Version            string     `json:"version"`
+	CipherSuite        string     `json:"cipher_suite"`
+	ServerName         string     `json:"server_name"`
+	NegotiatedProtocol string     `json:"negotiated_protocol"`
+	Resumed            bool       `json:"resumed"`
+	PeerCertificates   []CertInfo `json:"peer_certificates"`
+}
+
+type CertInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	Serial    string    `json:"serial"`
+}
+
+// 基于httptrace记录各阶段耗时
+type sendTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	requestStart time.Time
+	timings      SendTimings
+}
+
+func newSendTracer() *sendTracer {
+	return &sendTracer{start: time.Now()}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (t *sendTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			t.requestStart = time.Now()
+			t.timings.DNSMs, t.timings.ConnectMs, t.timings.TLSMs = 0, 0, 0
+			t.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.timings.DNSMs = durationMs(time.Since(t.dnsStart))
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			t.connectStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.timings.ConnectMs = durationMs(time.Since(t.connectStart))
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.timings.TLSMs = durationMs(time.Since(t.tlsStart))
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.timings.ConnReused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.timings.TTFBMs = durationMs(time.Since(t.requestStart))
+			t.mu.Unlock()
+		},
+	}
+}
+
+func (t *sendTracer) finish() SendTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings.TotalMs = durationMs(time.Since(t.start))
+	return t.timings
+}
+
+// 包装客户端以记录重定向链，不修改原客户端
+func withRedirectCapture(client *http.Client, hops *[]RedirectHop) *http.Client {
+	wrapped := *client
+	next := client.CheckRedirect
+	wrapped.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.Response != nil {
+			*hops = append(*hops, RedirectHop{
+				URL:      via[len(via)-1].URL.String(),
+				Status:   req.Response.StatusCode,
+				Location: req.Response.Header.Get("Location"),
+			})
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		// 与http.Client默认策略一致
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &wrapped
+}
+
+// 导出实际发送的原始请求（包含传输层补充的请求头），请求体过长时截断
+func dumpRawRequest(req *http.Request) string {
+	withBody := req.ContentLength <= maxRawRequestBody
+	raw, err := httputil.DumpRequestOut(req, withBody)
+	if err != nil {
+		return ""
+	}
+	if !withBody {
+		return string(raw) + "(请求体过长，已省略)"
+	}
+	return string(raw)
+}
+
+func tlsInfoFromState(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+	info := &TLSInfo{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		ServerName:         state.ServerName,
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		Resumed:            state.DidResume,
+	}
+	for _, cert := range state.PeerCertificates {
+		info.PeerCertificates = append(info.PeerCertificates, CertInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			Serial:    cert.SerialNumber.String(),
+		})
+	}
+	return info
+}
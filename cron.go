@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cron表达式，支持5段（分 时 日 月 周）和6段（秒 分 时 日 月 周）两种格式
+type cronSchedule struct {
+	second uint64
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// 日和周都被限制时，任一匹配即可（与标准cron一致）
+	domRestricted bool
+	dowRestricted bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var (
+	cronSecondField = cronField{0, 59}
+	cronMinuteField = cronField{0, 59}
+	cronHourField   = cronField{0, 23}
+	cronDomField    = cronField{1, 31}
+	cronMonthField  = cronField{1, 12}
+	cronDowField    = cronField{0, 7}
+)
+
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("cron表达式应为5段或6段: %s", expr)
+	}
+
+	s := &cronSchedule{}
+	var err error
+	if s.second, err = parseCronField(fields[0], cronSecondField); err != nil {
+		return nil, err
+	}
+	if s.minute, err = parseCronField(fields[1], cronMinuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[2], cronHourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[3], cronDomField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[4], cronMonthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[5], cronDowField); err != nil {
+		return nil, err
+	}
+	// 周日允许写作0或7
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	// 以*开头（包括*/2）视为不限制，与标准cron一致
+	s.domRestricted = !strings.HasPrefix(fields[3], "*") && fields[3] != "?"
+	s.dowRestricted = !strings.HasPrefix(fields[5], "*") && fields[5] != "?"
+	return s, nil
+}
+
+// 解析单个字段，支持 * ? , - / 语法
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron步长无效: %s", part)
+			}
+			step = n
+			part = part[:idx]
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("cron范围无效: %s", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("cron取值无效: %s", part)
+			}
+			lo = n
+			if step == 1 {
+				hi = n
+			}
+		}
+
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("cron取值超出范围 %d-%d: %s", f.min, f.max, part)
+		}
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// 计算严格晚于t的下一次触发时间，5年内无匹配时返回零值
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if s.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
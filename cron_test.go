@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04:05", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{"步长", "*/15 * * * *", "2024-01-01 10:07:30", "2024-01-01 10:15:00"},
+		{"严格晚于起始时间", "*/15 * * * *", "2024-01-01 10:15:00", "2024-01-01 10:30:00"},
+		{"跨小时", "*/15 * * * *", "2024-01-01 10:50:00", "2024-01-01 11:00:00"},
+		{"工作日范围", "0 9 * * 1-5", "2024-01-05 10:00:00", "2024-01-08 09:00:00"},
+		{"列表", "0 8,12,18 * * *", "2024-01-01 12:00:00", "2024-01-01 18:00:00"},
+		{"列表跨天", "0 8,12,18 * * *", "2024-01-01 18:00:00", "2024-01-02 08:00:00"},
+		{"范围带步长", "0 0-23/6 * * *", "2024-01-01 07:00:00", "2024-01-01 12:00:00"},
+		{"单值带步长", "0 5/7 * * *", "2024-01-01 06:00:00", "2024-01-01 12:00:00"},
+		{"6段秒级", "*/10 * * * * *", "2024-01-01 10:00:01", "2024-01-01 10:00:10"},
+		{"日和周任一匹配-周先到", "0 0 13 * 5", "2024-01-01 00:00:00", "2024-01-05 00:00:00"},
+		{"日和周任一匹配-日先到", "0 0 13 * 5", "2024-01-12 00:00:00", "2024-01-13 00:00:00"},
+		{"日为*/2时与周同时满足", "0 0 */2 * 1", "2024-01-01 00:00:00", "2024-01-15 00:00:00"},
+		{"只限制日", "0 0 31 * *", "2024-02-01 00:00:00", "2024-03-31 00:00:00"},
+		{"周日写作7", "0 0 * * 7", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"周日写作0", "0 0 * * 0", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"问号", "0 0 1 * ?", "2024-01-15 00:00:00", "2024-02-01 00:00:00"},
+		{"跨年", "0 0 1 1 *", "2024-06-01 00:00:00", "2025-01-01 00:00:00"},
+		{"闰日", "0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCron(%q): %v", tt.expr, err)
+			}
+			if got := schedule.next(at(tt.from)); !got.Equal(at(tt.want)) {
+				t.Errorf("parseCron(%q).next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	schedule, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("2月30日不应有触发时间，得到 %s", got)
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/-1 * * * *",
+		"5-1 * * * *",
+		"1-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) 应返回错误", expr)
+		}
+	}
+}
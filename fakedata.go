@@ -0,0 +1,822 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const endpointTypeFake = "fake"
+
+// 生成数据的嵌套深度上限，防止递归引用的schema无限展开
+const maxFakeDepth = 12
+
+// 一次最多生成的条数，也是单个数组的元素个数上限
+const maxFakeCount = 1000
+
+// 按minLength补齐的字符串长度上限
+const maxFakeLength = 10000
+
+// 一次生成的数组元素和补齐字符的总量上限，防止多层数组相乘后耗尽内存
+const maxFakeSize = 1000000
+
+// 日期类数据以此为基准，保证同一个种子生成的结果不随运行时间变化
+var fakeBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CST", 8*3600))
+
+// 动态数据接口配置。SchemaFile为JSON Schema，未设置时从ExampleFile（默认为接口的响应文件）推断结构。
+// Seed不为0时每次返回相同的数据；请求可用?_seed=指定种子，响应头X-Fake-Seed返回本次使用的种子
+type FakeResponseConfig struct {
+	SchemaFile  string `json:"schema_file,omitempty"`
+	ExampleFile string `json:"example_file,omitempty"`
+	Seed        int64  `json:"seed,omitempty"`
+	Count       int    `json:"count,omitempty"`
+}
+
+var (
+	fakeSurnames   = []rune("王李张刘陈杨黄赵吴周徐孙马朱胡郭何高林罗郑梁谢宋唐许韩冯邓曹彭曾肖田董袁潘蒋蔡余杜叶程苏魏吕丁任沈姚卢")
+	fakeGivenNames = []rune("伟芳娜秀英敏静丽强磊军洋勇艳杰娟涛明超秀兰霞平刚桂英华建国志红梅鑫宇浩然子涵欣怡思远嘉怡梓萱雨泽晨轩")
+	fakeProvinces  = []struct {
+		name   string
+		cities []string
+	}{
+		{"北京市", []string{"北京市"}},
+		{"上海市", []string{"上海市"}},
+		{"广东省", []string{"广州市", "深圳市", "珠海市", "佛山市"}},
+		{"浙江省", []string{"杭州市", "宁波市", "温州市"}},
+		{"江苏省", []string{"南京市", "苏州市", "无锡市"}},
+		{"四川省", []string{"成都市", "绵阳市"}},
+		{"湖北省", []string{"武汉市", "宜昌市"}},
+		{"山东省", []string{"济南市", "青岛市", "烟台市"}},
+	}
+	fakeDistricts    = []string{"高新区", "城东区", "城西区", "城南区", "城北区", "新城区", "经济开发区", "滨江区"}
+	fakeRoads        = []string{"人民路", "中山路", "解放路", "建设路", "和平路", "长江路", "文化路", "科技路", "新华路", "光明路"}
+	fakeCompanyWords = []string{"华信", "中科", "鼎盛", "创新", "智联", "云帆", "恒通", "星河", "天成", "博远"}
+	fakeCompanyTypes = []string{"科技有限公司", "信息技术有限公司", "网络科技有限公司", "数据服务有限公司", "传媒有限公司"}
+	fakeWords        = []string{"系统", "数据", "服务", "测试", "审核", "任务", "视频", "用户", "订单", "消息", "平台", "接口", "配置", "结果", "内容", "处理", "完成", "更新"}
+	fakeDomains      = []string{"example.com", "test.cn", "mail.example.org", "demo.net"}
+	fakeMobilePrefix = []string{"130", "131", "132", "135", "136", "137", "138", "139", "150", "151", "152", "158", "159", "176", "180", "186", "188", "189"}
+)
+
+var (
+	fakeUUIDPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	fakeEmailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	fakeIPv4Pattern     = regexp.MustCompile(`^\d{1,3}(\.\d{1,3}){3}$`)
+	fakeDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	fakeDigitsIDPattern = regexp.MustCompile(`^\d{12,}$`)
+)
+
+// 按JSON Schema生成数据的生成器，同一个种子生成相同的结果
+type fakeGenerator struct {
+	rand *rand.Rand
+	root map[string]interface{}
+	size int
+}
+
+func newFakeGenerator(seed int64, root map[string]interface{}) *fakeGenerator {
+	return &fakeGenerator{rand: rand.New(rand.NewSource(seed)), root: root}
+}
+
+func (g *fakeGenerator) pick(items []string) string {
+	return items[g.rand.Intn(len(items))]
+}
+
+func (g *fakeGenerator) chineseName() string {
+	name := string(fakeSurnames[g.rand.Intn(len(fakeSurnames))])
+	for i := 0; i < 1+g.rand.Intn(2); i++ {
+		name += string(fakeGivenNames[g.rand.Intn(len(fakeGivenNames))])
+	}
+	return name
+}
+
+func (g *fakeGenerator) province() (string, string) {
+	p := fakeProvinces[g.rand.Intn(len(fakeProvinces))]
+	return p.name, p.cities[g.rand.Intn(len(p.cities))]
+}
+
+func (g *fakeGenerator) address() string {
+	province, city := g.province()
+	if province == city {
+		province = ""
+	}
+	return fmt.Sprintf("%s%s%s%s%d号", province, city, g.pick(fakeDistricts), g.pick(fakeRoads), 1+g.rand.Intn(999))
+}
+
+func (g *fakeGenerator) phone() string {
+	return fmt.Sprintf("%s%08d", g.pick(fakeMobilePrefix), g.rand.Intn(100000000))
+}
+
+func (g *fakeGenerator) uuid() string {
+	var b [16]byte
+	g.rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (g *fakeGenerator) dateTime() time.Time {
+	return fakeBaseTime.Add(time.Duration(g.rand.Int63n(int64(730 * 24 * time.Hour))))
+}
+
+func (g *fakeGenerator) sentence() string {
+	var b strings.Builder
+	for i := 0; i < 3+g.rand.Intn(4); i++ {
+		b.WriteString(g.pick(fakeWords))
+	}
+	return b.String()
+}
+
+// 按格式或语义生成字符串，kind为空或未知时返回false
+func (g *fakeGenerator) fakeString(kind string) (string, bool) {
+	switch kind {
+	case "uuid":
+		return g.uuid(), true
+	case "date-time":
+		return g.dateTime().Format(time.RFC3339), true
+	case "date":
+		return g.dateTime().Format("2006-01-02"), true
+	case "time":
+		return g.dateTime().Format("15:04:05"), true
+	case "email":
+		return fmt.Sprintf("user%d@%s", 100+g.rand.Intn(9900), g.pick(fakeDomains)), true
+	case "uri", "url":
+		return fmt.Sprintf("http://%s/files/%d/%s", g.pick(fakeDomains), g.rand.Intn(1000), g.uuid()[:8]), true
+	case "hostname":
+		return fmt.Sprintf("host%d.%s", g.rand.Intn(100), g.pick(fakeDomains)), true
+	case "ipv4":
+		return fmt.Sprintf("192.168.%d.%d", g.rand.Intn(256), 1+g.rand.Intn(254)), true
+	case "name":
+		return g.chineseName(), true
+	case "phone":
+		return g.phone(), true
+	case "address":
+		return g.address(), true
+	case "province":
+		province, _ := g.province()
+		return province, true
+	case "city":
+		_, city := g.province()
+		return city, true
+	case "company":
+		_, city := g.province()
+		return strings.TrimSuffix(city, "市") + g.pick(fakeCompanyWords) + g.pick(fakeCompanyTypes), true
+	case "sentence":
+		return g.sentence(), true
+	case "id":
+		return strconv.FormatInt(1e18+g.rand.Int63n(8e18), 10), true
+	}
+	return "", false
+}
+
+// 根据字段名猜测字符串的语义，如name生成中文姓名，address生成中文地址
+func fakeKindForKey(key string) string {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	switch {
+	case k == "":
+		return ""
+	case strings.Contains(k, "email") || strings.Contains(k, "mail"):
+		return "email"
+	case strings.Contains(k, "phone") || strings.Contains(k, "mobile") || k == "tel" || strings.Contains(k, "电话"):
+		return "phone"
+	case strings.Contains(k, "address") || strings.Contains(k, "addr") || strings.Contains(k, "地址"):
+		return "address"
+	case strings.Contains(k, "province") || strings.Contains(k, "省"):
+		return "province"
+	case strings.Contains(k, "city") || strings.Contains(k, "城市"):
+		return "city"
+	case strings.Contains(k, "company") || strings.Contains(k, "公司"):
+		return "company"
+	case strings.Contains(k, "url") || strings.Contains(k, "uri") || strings.Contains(k, "link") || strings.Contains(k, "href"):
+		return "uri"
+	case strings.Contains(k, "uuid") || strings.Contains(k, "guid"):
+		return "uuid"
+	case k == "ip" || strings.HasSuffix(k, "ip") && !strings.HasSuffix(k, "ship"):
+		return "ipv4"
+	case strings.HasSuffix(k, "date") || strings.Contains(k, "birthday"):
+		return "date"
+	case strings.HasSuffix(k, "time") || strings.HasSuffix(k, "at") && len(k) > 2 && (strings.HasSuffix(key, "_at") || strings.HasSuffix(key, "At")):
+		return "date-time"
+	case strings.Contains(k, "filename") || strings.Contains(k, "hostname"):
+		return ""
+	case strings.Contains(k, "name") || strings.Contains(k, "姓名"):
+		return "name"
+	case strings.Contains(k, "desc") || strings.Contains(k, "remark") || strings.Contains(k, "comment") || strings.Contains(k, "content") || strings.Contains(k, "message") || strings.Contains(k, "text"):
+		return "sentence"
+	case strings.HasSuffix(k, "id"):
+		return "id"
+	}
+	return ""
+}
+
+// 解析本地引用#/definitions/xxx或#/$defs/xxx
+func (g *fakeGenerator) resolveRef(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("只支持本地引用: %s", ref)
+	}
+	var node interface{} = g.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("引用不存在: %s", ref)
+		}
+		if node, ok = object[part]; !ok {
+			return nil, fmt.Errorf("引用不存在: %s", ref)
+		}
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("引用不是schema: %s", ref)
+	}
+	return schema, nil
+}
+
+func schemaNumber(schema map[string]interface{}, key string) (float64, bool) {
+	value, ok := schema[key].(float64)
+	return value, ok
+}
+
+func schemaInt(schema map[string]interface{}, key string, fallback int) int {
+	if value, ok := schemaNumber(schema, key); ok {
+		return int(value)
+	}
+	return fallback
+}
+
+// 取schema的类型，类型数组取第一个非null类型
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+		if len(t) > 0 {
+			s, _ := t[0].(string)
+			return s
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// 按schema生成一个值，key为所在的字段名，用于猜测语义
+func (g *fakeGenerator) generate(schema map[string]interface{}, key string, depth int) (interface{}, error) {
+	if depth > maxFakeDepth {
+		return nil, nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := g.resolveRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		return g.generate(resolved, key, depth+1)
+	}
+	if value, ok := schema["const"]; ok {
+		return value, nil
+	}
+	if values, ok := schema["enum"].([]interface{}); ok && len(values) > 0 {
+		return values[g.rand.Intn(len(values))], nil
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[keyword].([]interface{}); ok && len(options) > 0 {
+			if option, ok := options[g.rand.Intn(len(options))].(map[string]interface{}); ok {
+				return g.generate(option, key, depth+1)
+			}
+		}
+	}
+	if parts, ok := schema["allOf"].([]interface{}); ok && len(parts) > 0 {
+		return g.generate(mergeAllOf(schema, parts), key, depth+1)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return g.generateObject(schema, depth)
+	case "array":
+		return g.generateArray(schema, key, depth)
+	case "integer":
+		return g.generateInteger(schema, key)
+	case "number":
+		return g.generateNumber(schema), nil
+	case "boolean":
+		return g.rand.Intn(2) == 1, nil
+	case "null":
+		return nil, nil
+	case "string":
+		return g.generateString(schema, key)
+	}
+	if examples, ok := schema["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[g.rand.Intn(len(examples))], nil
+	}
+	return nil, nil
+}
+
+// 合并allOf中的对象schema，属性和必填字段取并集
+func mergeAllOf(schema map[string]interface{}, parts []interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	properties := map[string]interface{}{}
+	for _, part := range append([]interface{}{schema}, parts...) {
+		partSchema, ok := part.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k, v := range partSchema {
+			switch k {
+			case "allOf":
+			case "properties":
+				if props, ok := v.(map[string]interface{}); ok {
+					for name, prop := range props {
+						properties[name] = prop
+					}
+				}
+			default:
+				merged[k] = v
+			}
+		}
+	}
+	if len(properties) > 0 {
+		merged["properties"] = properties
+	}
+	return merged
+}
+
+func (g *fakeGenerator) generateObject(schema map[string]interface{}, depth int) (interface{}, error) {
+	result := map[string]interface{}{}
+	properties, _ := schema["properties"].(map[string]interface{})
+	// 按字段名顺序生成，map的遍历顺序不固定，否则同一个种子结果会不同
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propSchema, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, err := g.generate(propSchema, name, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+func (g *fakeGenerator) generateArray(schema map[string]interface{}, key string, depth int) (interface{}, error) {
+	// 先按浮点数检查，超出int范围的值转换后结果不确定
+	for _, name := range []string{"minItems", "maxItems"} {
+		if v, ok := schemaNumber(schema, name); ok && v < 0 {
+			return nil, fmt.Errorf("minItems和maxItems不能为负数")
+		}
+	}
+	if v, ok := schemaNumber(schema, "minItems"); ok && v > maxFakeCount {
+		return nil, fmt.Errorf("minItems不能超过%d", maxFakeCount)
+	}
+	maxItems := -1
+	if v, ok := schemaNumber(schema, "maxItems"); ok {
+		maxItems = int(math.Min(v, maxFakeCount))
+	}
+	// 只给了maxItems时minItems不能超过它，否则maxItems为0时也会生成一个元素
+	minItems := schemaInt(schema, "minItems", 1)
+	if _, ok := schemaNumber(schema, "minItems"); !ok && maxItems >= 0 && maxItems < minItems {
+		minItems = maxItems
+	}
+	if maxItems < 0 {
+		maxItems = minItems + 4
+	}
+	if maxItems < minItems {
+		maxItems = minItems
+	}
+	count := minItems + g.rand.Intn(maxItems-minItems+1)
+	if err := g.grow(count); err != nil {
+		return nil, err
+	}
+	items, _ := schema["items"].(map[string]interface{})
+	result := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		if items == nil {
+			result = append(result, g.sentence())
+			continue
+		}
+		value, err := g.generate(items, key, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func hasNumberBounds(schema map[string]interface{}) bool {
+	for _, key := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if _, ok := schemaNumber(schema, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// 计入本次生成的数据量，超过maxFakeSize时返回错误
+func (g *fakeGenerator) grow(n int) error {
+	g.size += n
+	if g.size > maxFakeSize {
+		return fmt.Errorf("生成的数据量超过上限%d，请减小minItems、maxItems或minLength", maxFakeSize)
+	}
+	return nil
+}
+
+// 数值范围，exclusiveMinimum/exclusiveMaximum按draft 6以后的数值形式处理
+func numberRange(schema map[string]interface{}, defaultMin, defaultMax float64) (float64, float64) {
+	min, hasMin := schemaNumber(schema, "minimum")
+	max, hasMax := schemaNumber(schema, "maximum")
+	if v, ok := schemaNumber(schema, "exclusiveMinimum"); ok {
+		min, hasMin = v+1e-9, true
+	}
+	if v, ok := schemaNumber(schema, "exclusiveMaximum"); ok {
+		max, hasMax = v-1e-9, true
+	}
+	switch {
+	case !hasMin && !hasMax:
+		min, max = defaultMin, defaultMax
+	case !hasMin:
+		min = math.Min(defaultMin, max)
+		if max-min > defaultMax-defaultMin {
+			min = max - (defaultMax - defaultMin)
+		}
+	case !hasMax:
+		max = min + (defaultMax - defaultMin)
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+func (g *fakeGenerator) generateInteger(schema map[string]interface{}, key string) (interface{}, error) {
+	defaultMin, defaultMax := 1.0, 1000.0
+	k := strings.ToLower(key)
+	switch {
+	case (strings.Contains(k, "timestamp") || strings.HasSuffix(k, "time")) && !hasNumberBounds(schema):
+		// 毫秒时间戳，schema自己给了范围时以范围为准
+		return g.dateTime().UnixMilli(), nil
+	case k == "age" || strings.HasSuffix(k, "_age"):
+		defaultMin, defaultMax = 18, 65
+	case strings.HasSuffix(k, "id"):
+		defaultMin, defaultMax = 1, 100000
+	}
+	min, max := numberRange(schema, defaultMin, defaultMax)
+	// float64转int64超出范围时结果不确定，2^63本身也超出int64
+	if min < math.MinInt64 || max >= -math.MinInt64 {
+		return nil, fmt.Errorf("整数范围超出int64: [%v, %v]", min, max)
+	}
+	lo, hi := int64(math.Ceil(min)), int64(math.Floor(max))
+	if hi < lo {
+		hi = lo
+	}
+	var value int64
+	if span := hi - lo; span < 0 || span == math.MaxInt64 {
+		// 跨度超过int64时hi-lo+1溢出，Int63n会panic；此时lo+Int63()一定在范围内
+		value = lo + g.rand.Int63()
+	} else {
+		value = lo + g.rand.Int63n(span+1)
+	}
+	if step, ok := schemaNumber(schema, "multipleOf"); ok && step >= 1 {
+		s := int64(step)
+		value = value / s * s
+		if value < lo {
+			value += s
+		}
+	}
+	return value, nil
+}
+
+func (g *fakeGenerator) generateNumber(schema map[string]interface{}) interface{} {
+	min, max := numberRange(schema, 0, 1000)
+	value := min + g.rand.Float64()*(max-min)
+	if step, ok := schemaNumber(schema, "multipleOf"); ok && step > 0 {
+		return math.Round(value/step) * step
+	}
+	return math.Round(value*100) / 100
+}
+
+func (g *fakeGenerator) generateString(schema map[string]interface{}, key string) (interface{}, error) {
+	if v, ok := schemaNumber(schema, "minLength"); ok && v > maxFakeLength {
+		return nil, fmt.Errorf("minLength不能超过%d", maxFakeLength)
+	}
+	var value string
+	var ok bool
+	if kind, has := schema["x-fake"].(string); has {
+		value, ok = g.fakeString(kind)
+	}
+	if format, has := schema["format"].(string); has && !ok {
+		value, ok = g.fakeString(format)
+	}
+	if examples, has := schema["examples"].([]interface{}); has && len(examples) > 0 && !ok {
+		return examples[g.rand.Intn(len(examples))], nil
+	}
+	if !ok {
+		value, ok = g.fakeString(fakeKindForKey(key))
+	}
+	if !ok {
+		value = g.sentence()
+	}
+
+	// 按长度限制截断或补齐
+	runes := []rune(value)
+	if maxLength := schemaInt(schema, "maxLength", -1); maxLength >= 0 && len(runes) > maxLength {
+		runes = runes[:maxLength]
+	}
+	minLength := schemaInt(schema, "minLength", 0)
+	if len(runes) < minLength {
+		if err := g.grow(minLength - len(runes)); err != nil {
+			return nil, err
+		}
+	}
+	for len(runes) < minLength {
+		runes = append(runes, []rune(g.pick(fakeWords))...)
+		if maxLength := schemaInt(schema, "maxLength", -1); maxLength >= 0 && len(runes) > maxLength {
+			runes = runes[:maxLength]
+		}
+	}
+	return string(runes), nil
+}
+
+// 从示例数据推断schema，字符串按格式和字段名识别语义，识别不出时保留示例值
+func inferSchema(value interface{}, key string) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := map[string]interface{}{}
+		for name, item := range v {
+			properties[name] = inferSchema(item, name)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case []interface{}:
+		schema := map[string]interface{}{"type": "array", "minItems": float64(1), "maxItems": float64(len(v) + 2)}
+		// 合并所有元素的结构，字段取并集，示例值和数值范围也合并
+		var items map[string]interface{}
+		for _, item := range v {
+			items = mergeInferredSchema(items, inferSchema(item, key))
+		}
+		if items != nil {
+			schema["items"] = items
+		}
+		return schema
+	case string:
+		schema := map[string]interface{}{"type": "string"}
+		switch {
+		case fakeUUIDPattern.MatchString(v):
+			schema["format"] = "uuid"
+		case fakeEmailPattern.MatchString(v):
+			schema["format"] = "email"
+		case fakeIPv4Pattern.MatchString(v):
+			schema["format"] = "ipv4"
+		case fakeDatePattern.MatchString(v):
+			schema["format"] = "date"
+		case strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://"):
+			schema["format"] = "uri"
+		case fakeDigitsIDPattern.MatchString(v):
+			schema["x-fake"] = "id"
+		default:
+			if _, err := time.Parse(time.RFC3339, v); err == nil {
+				schema["format"] = "date-time"
+			} else if kind := fakeKindForKey(key); kind == "" || kind == "id" {
+				// 非数字的id如audio_task_001保留示例值
+				schema["examples"] = []interface{}{v}
+			}
+		}
+		return schema
+	case float64:
+		if v == math.Trunc(v) {
+			schema := map[string]interface{}{"type": "integer"}
+			if v > 0 && !strings.Contains(strings.ToLower(key), "time") {
+				schema["minimum"] = math.Max(1, math.Floor(v/2))
+				schema["maximum"] = v * 2
+			} else if v == 0 {
+				schema["minimum"], schema["maximum"] = float64(0), float64(100)
+			}
+			return schema
+		}
+		if v >= 0 && v <= 1 {
+			// 比例、置信度之类的小数
+			return map[string]interface{}{"type": "number", "minimum": float64(0), "maximum": float64(1)}
+		}
+		return map[string]interface{}{"type": "number", "minimum": math.Min(0, v), "maximum": math.Abs(v) * 2}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	}
+	return map[string]interface{}{"type": "null"}
+}
+
+// 合并两个推断出的schema，类型不同时保留前一个
+func mergeInferredSchema(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		return b
+	}
+	if a["type"] != b["type"] {
+		return a
+	}
+	if props, ok := a["properties"].(map[string]interface{}); ok {
+		other, _ := b["properties"].(map[string]interface{})
+		for name, prop := range other {
+			existing, _ := props[name].(map[string]interface{})
+			props[name] = mergeInferredSchema(existing, prop.(map[string]interface{}))
+		}
+	}
+	if items, ok := b["items"].(map[string]interface{}); ok {
+		existing, _ := a["items"].(map[string]interface{})
+		a["items"] = mergeInferredSchema(existing, items)
+	}
+	if examples, ok := a["examples"].([]interface{}); ok {
+		other, _ := b["examples"].([]interface{})
+		for _, example := range other {
+			found := false
+			for _, e := range examples {
+				if e == example {
+					found = true
+					break
+				}
+			}
+			if !found {
+				examples = append(examples, example)
+			}
+		}
+		a["examples"] = examples
+	}
+	if min, ok := b["minimum"].(float64); ok {
+		if current, has := a["minimum"].(float64); has && min < current {
+			a["minimum"] = min
+		}
+	}
+	if max, ok := b["maximum"].(float64); ok {
+		if current, has := a["maximum"].(float64); has && max > current {
+			a["maximum"] = max
+		}
+	}
+	return a
+}
+
+// 读取schema文件或从示例文件推断schema
+func loadFakeSchema(project, schemaFile, exampleFile string) (map[string]interface{}, error) {
+	if schemaFile != "" {
+		data, err := readProjectFile(project, schemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取schema失败: %v", err)
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("schema不是有效的JSON对象: %v", err)
+		}
+		return schema, nil
+	}
+	if exampleFile != "" {
+		data, err := readProjectFile(project, exampleFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取示例文件失败: %v", err)
+		}
+		var example interface{}
+		if err := json.Unmarshal(data, &example); err != nil {
+			return nil, fmt.Errorf("示例文件不是有效的JSON: %v", err)
+		}
+		return inferSchema(example, ""), nil
+	}
+	return nil, errors.New("需要指定schema或示例文件")
+}
+
+// 生成count条数据，count为0时返回单个值
+func generateFakeData(schema map[string]interface{}, seed int64, count int) (interface{}, error) {
+	g := newFakeGenerator(seed, schema)
+	if count <= 0 {
+		return g.generate(schema, "", 0)
+	}
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		item, err := g.generate(schema, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// 未指定种子时随机选取，返回给调用方以便复现
+func randomFakeSeed() int64 {
+	return time.Now().UnixNano() % 1e12
+}
+
+// API: 按schema或示例文件生成数据，指定save_as时保存为项目文件
+func generateFakeFixture(c *gin.Context) {
+	var request struct {
+		Schema      map[string]interface{} `json:"schema"`
+		SchemaFile  string                 `json:"schema_file"`
+		ExampleFile string                 `json:"example_file"`
+		Seed        int64                  `json:"seed"`
+		Count       int                    `json:"count"`
+		SaveAs      string                 `json:"save_as"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Count > maxFakeCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("count不能超过%d", maxFakeCount)})
+		return
+	}
+
+	schema := request.Schema
+	if schema == nil {
+		var err error
+		if schema, err = loadFakeSchema(currentProject, request.SchemaFile, request.ExampleFile); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	seed := request.Seed
+	if seed == 0 {
+		seed = randomFakeSeed()
+	}
+	data, err := generateFakeData(schema, seed, request.Count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "生成数据失败: " + err.Error()})
+		return
+	}
+
+	if request.SaveAs != "" {
+		if _, ok := projectFileParam(c, request.SaveAs); !ok {
+			return
+		}
+		content, _ := json.MarshalIndent(data, "", "    ")
+		if err := writeVersionedFile(currentProject, projectFileRevisionPath(request.SaveAs), content, revisionActionSave); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败: " + err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"seed": seed, "data": data, "saved": request.SaveAs})
+}
+
+// API: 从示例文件推断schema，可以保存后再手工调整
+func inferFakeSchema(c *gin.Context) {
+	schema, err := loadFakeSchema(currentProject, "", c.Query("file"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
+
+// 动态数据接口：每次请求按schema生成新数据
+func handleFakeEndpoint(c *gin.Context, path, responseFile string, config *FakeResponseConfig) {
+	recordIncomingRequest(c, path)
+	if config == nil {
+		config = &FakeResponseConfig{}
+	}
+	exampleFile := config.ExampleFile
+	if exampleFile == "" {
+		exampleFile = responseFile
+	}
+	schema, err := loadFakeSchema(currentProject, config.SchemaFile, exampleFile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	seed := config.Seed
+	if s, err := strconv.ParseInt(c.Query("_seed"), 10, 64); err == nil && s != 0 {
+		seed = s
+	}
+	if seed == 0 {
+		seed = randomFakeSeed()
+	}
+	count := config.Count
+	if count > maxFakeCount {
+		count = maxFakeCount
+	}
+	data, err := generateFakeData(schema, seed, count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成数据失败: " + err.Error()})
+		return
+	}
+	c.Header("X-Fake-Seed", strconv.FormatInt(seed, 10))
+	c.JSON(http.StatusOK, data)
+}
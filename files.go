@@ -0,0 +1,414 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 单次上传的大小上限
+const maxFileUploadSize = 100 << 20
+
+// 文件类型，json和xml保存时校验格式，image和binary不能在线编辑
+const (
+	fileTypeJSON      = "json"
+	fileTypeXML       = "xml"
+	fileTypeText      = "text"
+	fileTypeImage     = "image"
+	fileTypeBinary    = "binary"
+	fileTypeDirectory = "directory"
+)
+
+// 系统MIME表中不一定有的常见类型
+var assetMimeTypes = map[string]string{
+	".json": "application/json; charset=utf-8",
+	".xml":  "application/xml; charset=utf-8",
+	".txt":  "text/plain; charset=utf-8",
+	".log":  "text/plain; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".csv":  "text/csv; charset=utf-8",
+	".yaml": "application/yaml; charset=utf-8",
+	".yml":  "application/yaml; charset=utf-8",
+	".html": "text/html; charset=utf-8",
+	".js":   "text/javascript; charset=utf-8",
+	".svg":  "image/svg+xml",
+}
+
+// 项目文件的元数据，Name为相对文件目录的路径，用/分隔
+type ProjectFileInfo struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Type     string    `json:"type"`
+	MimeType string    `json:"mime_type,omitempty"`
+	IsDir    bool      `json:"is_dir,omitempty"`
+}
+
+// 解析项目内文件的路径，可以带子目录，禁止跳出项目文件目录和访问隐藏文件
+func resolveProjectFile(project, name string) (string, error) {
+	if name == "" || strings.Contains(name, "\\") || path.IsAbs(name) {
+		return "", errors.New("非法文件名")
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." || strings.HasPrefix(part, ".") {
+			return "", errors.New("非法文件名")
+		}
+	}
+	return filepath.Join(getJSONFilesPath(project), filepath.FromSlash(name)), nil
+}
+
+// 读取项目内的文件，响应文件、发送文件等统一通过这里读取
+func readProjectFile(project, name string) ([]byte, error) {
+	filePath, err := resolveProjectFile(project, name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, name)
+	}
+	return os.ReadFile(filePath)
+}
+
+// 按扩展名取MIME类型，未知扩展名返回空
+func extensionMimeType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if mimeType, ok := assetMimeTypes[ext]; ok {
+		return mimeType
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// 按扩展名取MIME类型，未知类型根据内容判断
+func assetMimeType(name string, data []byte) string {
+	if mimeType := extensionMimeType(name); mimeType != "" {
+		return mimeType
+	}
+	if data != nil {
+		return http.DetectContentType(data)
+	}
+	return "application/octet-stream"
+}
+
+func assetFileType(name, mimeType string) string {
+	switch {
+	case strings.EqualFold(filepath.Ext(name), ".json"):
+		return fileTypeJSON
+	case isXMLFile(name):
+		return fileTypeXML
+	case strings.HasPrefix(mimeType, "image/"):
+		return fileTypeImage
+	case strings.HasPrefix(mimeType, "text/"), strings.Contains(mimeType, "charset="):
+		return fileTypeText
+	}
+	return fileTypeBinary
+}
+
+// 保存前按类型校验内容，JSON和XML必须格式正确
+func validateAssetContent(name string, data []byte) error {
+	switch assetFileType(name, assetMimeType(name, nil)) {
+	case fileTypeJSON:
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("JSON格式错误: %v", err)
+		}
+	case fileTypeXML:
+		if err := validateXML(data); err != nil {
+			return fmt.Errorf("XML格式错误: %v", err)
+		}
+	}
+	return nil
+}
+
+// 文件目录内的相对路径转换为版本历史中的路径
+func projectFileRevisionPath(name string) string {
+	return "json_files/" + name
+}
+
+// 读取请求中的文件路径，失败时已写入响应
+func projectFileParam(c *gin.Context, name string) (string, bool) {
+	filePath, err := resolveProjectFile(currentProject, name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return filePath, true
+}
+
+// API: 列出项目文件和子目录，包含大小、修改时间和类型
+func listJSONFiles(c *gin.Context) {
+	root := getJSONFilesPath(currentProject)
+	files := []ProjectFileInfo{}
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && filePath == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if filePath == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, filePath)
+		entry := ProjectFileInfo{
+			Name:     filepath.ToSlash(rel),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		}
+		if d.IsDir() {
+			entry.Type = fileTypeDirectory
+			entry.IsDir = true
+			entry.Size = 0
+		} else if d.Type().IsRegular() {
+			entry.MimeType = assetMimeType(d.Name(), nil)
+			entry.Type = assetFileType(d.Name(), entry.MimeType)
+		} else {
+			return nil
+		}
+		files = append(files, entry)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	c.JSON(http.StatusOK, files)
+}
+
+// API: 删除文件或空目录，删除前的文件内容保留在版本历史中
+func deleteProjectFile(c *gin.Context) {
+	name := c.Query("file")
+	filePath, ok := projectFileParam(c, name)
+	if !ok {
+		return
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+
+	if info.IsDir() {
+		entries, _ := os.ReadDir(filePath)
+		if len(entries) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "目录不为空"})
+			return
+		}
+		err = os.Remove(filePath)
+	} else {
+		err = removeVersionedFile(currentProject, projectFileRevisionPath(name))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 读取源路径和目标路径，目标已存在时返回409，失败时已写入响应
+func projectFileMoveParams(c *gin.Context) (from, to, fromPath, toPath string, ok bool) {
+	var request struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fromPath, ok = projectFileParam(c, request.From); !ok {
+		return
+	}
+	if _, err := os.Stat(fromPath); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return "", "", "", "", false
+	}
+	if request.To == "" {
+		return request.From, "", fromPath, "", true
+	}
+	if toPath, ok = projectFileParam(c, request.To); !ok {
+		return
+	}
+	if _, err := os.Stat(toPath); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "目标文件已存在: " + request.To})
+		return "", "", "", "", false
+	}
+	return request.From, request.To, fromPath, toPath, true
+}
+
+// API: 重命名或移动文件、目录，接口配置中引用的文件名不会自动更新
+func renameProjectFile(c *gin.Context) {
+	_, to, fromPath, toPath, ok := projectFileMoveParams(c)
+	if !ok {
+		return
+	}
+	if to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "新文件名不能为空"})
+		return
+	}
+	if strings.HasPrefix(toPath, fromPath+string(filepath.Separator)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能移动到自身的子目录"})
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(toPath), 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := os.Rename(fromPath, toPath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "重命名失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "重命名成功", "name": to})
+}
+
+// API: 复制文件，未指定目标时在同目录生成xxx-copy.json
+func duplicateProjectFile(c *gin.Context) {
+	from, to, fromPath, _, ok := projectFileMoveParams(c)
+	if !ok {
+		return
+	}
+	data, err := os.ReadFile(fromPath)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "只能复制文件: " + err.Error()})
+		return
+	}
+	if to == "" {
+		ext := path.Ext(from)
+		base := strings.TrimSuffix(from, ext) + "-copy"
+		to = base + ext
+		for i := 2; ; i++ {
+			toPath, _ := resolveProjectFile(currentProject, to)
+			if _, err := os.Stat(toPath); err != nil {
+				break
+			}
+			to = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+	}
+
+	if err := writeVersionedFile(currentProject, projectFileRevisionPath(to), data, revisionActionSave); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "复制失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "复制成功", "name": to})
+}
+
+// API: 创建子目录
+func createProjectFolder(c *gin.Context) {
+	var request struct {
+		Path string `json:"path"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dirPath, ok := projectFileParam(c, request.Path)
+	if !ok {
+		return
+	}
+	if _, err := os.Stat(dirPath); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "已存在: " + request.Path})
+		return
+	}
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建目录失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "目录创建成功"})
+}
+
+// API: 上传文件（multipart字段files，可多个），可选dir指定子目录，overwrite=true时覆盖同名文件
+func uploadProjectFiles(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileUploadSize)
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有上传文件或文件过大: " + err.Error()})
+		return
+	}
+	uploads := append(form.File["files"], form.File["file"]...)
+	if len(uploads) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有上传文件"})
+		return
+	}
+	dir := strings.Trim(c.PostForm("dir"), "/")
+	overwrite := c.PostForm("overwrite") == "true"
+
+	// 先全部校验，避免只上传了一部分
+	type pendingFile struct {
+		name string
+		data []byte
+	}
+	pending := make([]pendingFile, 0, len(uploads))
+	for _, upload := range uploads {
+		name := path.Base(filepath.ToSlash(upload.Filename))
+		if dir != "" {
+			name = dir + "/" + name
+		}
+		filePath, ok := projectFileParam(c, name)
+		if !ok {
+			return
+		}
+		if _, err := os.Stat(filePath); err == nil && !overwrite {
+			c.JSON(http.StatusConflict, gin.H{"error": "文件已存在: " + name})
+			return
+		}
+		file, err := upload.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := validateAssetContent(name, data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": name + ": " + err.Error()})
+			return
+		}
+		pending = append(pending, pendingFile{name: name, data: data})
+	}
+
+	names := make([]string, 0, len(pending))
+	for _, file := range pending {
+		if err := writeVersionedFile(currentProject, projectFileRevisionPath(file.name), file.data, revisionActionSave); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败: " + err.Error(), "files": names})
+			return
+		}
+		names = append(names, file.name)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("上传成功，共%d个文件", len(names)), "files": names})
+}
+
+// API: 下载文件
+func downloadProjectFile(c *gin.Context) {
+	name := c.Query("file")
+	filePath, ok := projectFileParam(c, name)
+	if !ok {
+		return
+	}
+	if info, err := os.Stat(filePath); err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+	c.FileAttachment(filePath, path.Base(name))
+}
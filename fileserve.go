@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const endpointTypeFile = "file"
+
+// 文件响应配置。Path为项目文件，未设置时使用接口的ResponseFile；
+// Path以/结尾时作为目录，接口路径需带通配符如/download/*filepath，请求的子路径映射到目录下的文件。
+// RateKBps大于0时限制下载速度（KB/s）
+type FileResponseConfig struct {
+	Path        string `json:"path,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Attachment  bool   `json:"attachment,omitempty"`
+	RateKBps    int    `json:"rate_kbps,omitempty"`
+}
+
+// 限速写入，按已写入的字节数计算应耗费的时间，写快了就等待
+type throttledWriter struct {
+	http.ResponseWriter
+	request *http.Request
+	rate    int
+	start   time.Time
+	written int64
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	// 每次最多写入约100ms的数据，让速度更平滑
+	chunk := w.rate / 10
+	if chunk < 512 {
+		chunk = 512
+	}
+	total := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > chunk {
+			n = chunk
+		}
+		written, err := w.ResponseWriter.Write(p[:n])
+		total += written
+		w.written += int64(written)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+
+		expected := time.Duration(w.written * int64(time.Second) / int64(w.rate))
+		if wait := expected - time.Since(w.start); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-w.request.Context().Done():
+				return total, w.request.Context().Err()
+			}
+		}
+	}
+	return total, nil
+}
+
+// 请求对应的项目文件，目录模式下取路由通配符匹配到的子路径
+func (config *FileResponseConfig) target(c *gin.Context, responseFile string) (string, error) {
+	name := config.Path
+	if name == "" {
+		name = responseFile
+	}
+	if name == "" {
+		return "", fmt.Errorf("未配置文件")
+	}
+	if !strings.HasSuffix(name, "/") {
+		return name, nil
+	}
+
+	sub := ""
+	for _, param := range c.Params {
+		// 通配符参数的值以/开头
+		if strings.HasPrefix(param.Value, "/") {
+			sub = param.Value
+		}
+	}
+	sub = strings.TrimPrefix(path.Clean("/"+sub), "/")
+	if sub == "" {
+		return "", fmt.Errorf("请求路径中没有文件名")
+	}
+	return strings.TrimSuffix(name, "/") + "/" + sub, nil
+}
+
+// 文件接口：流式返回项目文件，支持ETag、Last-Modified、Range分段和限速
+func handleFileEndpoint(c *gin.Context, path, responseFile string, config *FileResponseConfig) {
+	recordIncomingRequest(c, path)
+	if config == nil {
+		config = &FileResponseConfig{}
+	}
+
+	name, err := config.target(c, responseFile)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	filePath, err := resolveProjectFile(currentProject, name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在: " + name})
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在: " + name})
+		return
+	}
+
+	header := c.Writer.Header()
+	contentType := config.ContentType
+	if contentType == "" {
+		contentType = extensionMimeType(name)
+	}
+	// 未知类型不设置，由ServeContent根据内容判断
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("ETag", fmt.Sprintf(`"%x-%x"`, info.Size(), info.ModTime().UnixNano()))
+	if config.Attachment {
+		header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename*=UTF-8''%s`, url.PathEscape(filepath.Base(name))))
+	}
+
+	var w http.ResponseWriter = c.Writer
+	if config.RateKBps > 0 {
+		w = &throttledWriter{ResponseWriter: c.Writer, request: c.Request, rate: config.RateKBps * 1024, start: time.Now()}
+	}
+	http.ServeContent(w, c.Request, filepath.Base(name), info.ModTime(), file)
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+const endpointTypeGraphQL = "graphql"
+
+// 自动生成列表字段时的元素个数
+const defaultGraphQLListSize = 2
+
+// GraphQL接口配置。SchemaFile为项目graphql目录下的SDL文件；
+// Fixtures按根字段指定JSON文件，键为字段名或"Mutation.字段名"，
+// 没有fixture的字段和fixture中缺少的字段按schema自动生成
+type GraphQLConfig struct {
+	SchemaFile string            `json:"schema_file"`
+	Fixtures   map[string]string `json:"fixtures,omitempty"`
+	Rules      []GraphQLRule     `json:"rules,omitempty"`
+	ListSize   int               `json:"list_size,omitempty"`
+}
+
+// 按操作名和变量匹配的规则，按顺序取第一条命中的规则直接返回ResponseFile。
+// Variables只需列出要比较的变量
+type GraphQLRule struct {
+	OperationName string                 `json:"operation_name,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	ResponseFile  string                 `json:"response_file"`
+}
+
+// 请求日志中记录的GraphQL操作
+type GraphQLRequestInfo struct {
+	OperationName string                 `json:"operation_name,omitempty"`
+	OperationType string                 `json:"operation_type,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func getGraphQLPath(project string) string {
+	return filepath.Join(getProjectPath(project), "graphql")
+}
+
+func isGraphQLSchemaFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".graphql" || ext == ".graphqls" || ext == ".gql"
+}
+
+func loadGraphQLSchema(project, name string) (*ast.Schema, error) {
+	if name == "" {
+		return nil, errors.New("未配置schema文件")
+	}
+	if strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") {
+		return nil, fmt.Errorf("无效的文件名: %s", name)
+	}
+	data, err := os.ReadFile(filepath.Join(getGraphQLPath(project), name))
+	if err != nil {
+		return nil, err
+	}
+	return gqlparser.LoadSchema(&ast.Source{Name: name, Input: string(data)})
+}
+
+// 按GraphQL over HTTP的约定解析请求：GET读取查询参数，
+// application/graphql请求体即查询语句，其余按JSON解析
+func parseGraphQLRequest(method, contentType string, query url.Values, body []byte) (graphQLRequest, error) {
+	var req graphQLRequest
+	switch {
+	case method == http.MethodGet:
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, errors.New("variables不是有效的JSON对象")
+			}
+		}
+	case contentType == "application/graphql":
+		req.Query = string(body)
+		req.OperationName = query.Get("operationName")
+	default:
+		if err := json.Unmarshal(body, &req); err != nil {
+			return req, errors.New("请求体不是有效的GraphQL JSON: " + err.Error())
+		}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return req, errors.New("缺少query")
+	}
+	return req, nil
+}
+
+func (r GraphQLRule) matches(operationName string, variables map[string]interface{}) bool {
+	if r.OperationName != "" && r.OperationName != operationName {
+		return false
+	}
+	for k, v := range r.Variables {
+		if !reflect.DeepEqual(v, variables[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func graphQLErrors(messages ...string) gin.H {
+	errs := make([]gin.H, 0, len(messages))
+	for _, message := range messages {
+		errs = append(errs, gin.H{"message": message})
+	}
+	return gin.H{"errors": errs}
+}
+
+// GraphQL接口：规则命中时返回对应文件，否则按schema执行查询，
+// 字段值取自fixture或自动生成
+func handleGraphQLEndpoint(c *gin.Context, path string, config *GraphQLConfig) {
+	requestLog := newRequestLog(c, path)
+	status, response := resolveGraphQLRequest(c, config, []byte(requestLog.Body), &requestLog)
+	appendRequestLog(requestLog)
+	c.JSON(status, response)
+}
+
+func resolveGraphQLRequest(c *gin.Context, config *GraphQLConfig, body []byte, requestLog *RequestLog) (int, interface{}) {
+	if config == nil {
+		return http.StatusInternalServerError, graphQLErrors("GraphQL接口未配置")
+	}
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed, graphQLErrors("仅支持GET和POST请求")
+	}
+
+	req, err := parseGraphQLRequest(c.Request.Method, c.ContentType(), c.Request.URL.Query(), body)
+	if err != nil {
+		return http.StatusBadRequest, graphQLErrors(err.Error())
+	}
+	info := &GraphQLRequestInfo{OperationName: req.OperationName, Variables: req.Variables}
+	requestLog.GraphQL = info
+
+	// 先做语法解析以确定操作名，规则匹配不依赖schema
+	doc, err := parser.ParseQuery(&ast.Source{Input: req.Query})
+	if err != nil {
+		return http.StatusOK, gin.H{"errors": gqlerror.List{toGQLError(err)}}
+	}
+	op := doc.Operations.ForName(req.OperationName)
+	if op == nil {
+		if req.OperationName == "" {
+			return http.StatusOK, graphQLErrors("文档包含多个操作时必须指定operationName")
+		}
+		return http.StatusOK, graphQLErrors("操作不存在: " + req.OperationName)
+	}
+	info.OperationName = op.Name
+	info.OperationType = string(op.Operation)
+
+	project := currentProject
+	for _, rule := range config.Rules {
+		if rule.matches(op.Name, req.Variables) {
+			return http.StatusOK, readGraphQLResponseFile(project, rule.ResponseFile)
+		}
+	}
+
+	schema, err := loadGraphQLSchema(project, config.SchemaFile)
+	if err != nil {
+		return http.StatusInternalServerError, graphQLErrors("加载schema失败: " + err.Error())
+	}
+	if errs := validator.Validate(schema, doc); len(errs) > 0 {
+		return http.StatusOK, gin.H{"errors": errs}
+	}
+	if op.Operation == ast.Subscription {
+		return http.StatusOK, graphQLErrors("不支持subscription操作")
+	}
+	vars, err := validator.VariableValues(schema, op, req.Variables)
+	if err != nil {
+		return http.StatusOK, gin.H{"errors": gqlerror.List{toGQLError(err)}}
+	}
+
+	listSize := config.ListSize
+	if listSize <= 0 {
+		listSize = defaultGraphQLListSize
+	}
+	resolver := &graphQLResolver{
+		schema:   schema,
+		doc:      doc,
+		vars:     vars,
+		project:  project,
+		fixtures: config.Fixtures,
+		listSize: listSize,
+	}
+	data := resolver.execute(op)
+	response := graphQLObject{{"data", data}}
+	if len(resolver.errors) > 0 {
+		response = append(response, graphQLField{"errors", resolver.errors})
+	}
+	return http.StatusOK, response
+}
+
+func toGQLError(err error) *gqlerror.Error {
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	return gqlerror.Wrap(err)
+}
+
+// 规则的响应文件已包含data或errors时原样返回，否则作为data返回
+func readGraphQLResponseFile(project, name string) interface{} {
+	data, err := readProjectFile(project, name)
+	if err != nil {
+		return graphQLErrors("读取响应文件失败: " + err.Error())
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return graphQLErrors("响应文件不是有效的JSON: " + err.Error())
+	}
+	if obj, ok := value.(map[string]interface{}); ok {
+		if _, ok := obj["data"]; ok {
+			return obj
+		}
+		if _, ok := obj["errors"]; ok {
+			return obj
+		}
+	}
+	return gin.H{"data": value}
+}
+
+// 保持查询中字段顺序的JSON对象
+type graphQLField struct {
+	key   string
+	value interface{}
+}
+
+type graphQLObject []graphQLField
+
+func (o graphQLObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type graphQLResolver struct {
+	schema   *ast.Schema
+	doc      *ast.QueryDocument
+	vars     map[string]interface{}
+	project  string
+	fixtures map[string]string
+	listSize int
+	errors   []gin.H
+}
+
+func (r *graphQLResolver) execute(op *ast.OperationDefinition) interface{} {
+	root := r.schema.Query
+	if op.Operation == ast.Mutation {
+		root = r.schema.Mutation
+	}
+	if root == nil {
+		r.errors = append(r.errors, gin.H{"message": "schema未定义" + string(op.Operation) + "类型"})
+		return nil
+	}
+
+	result := graphQLObject{}
+	for _, field := range r.collectFields(root, op.SelectionSet) {
+		key := responseKey(field)
+		switch field.Name {
+		case "__typename":
+			result = append(result, graphQLField{key, root.Name})
+			continue
+		case "__schema", "__type":
+			r.errors = append(r.errors, gin.H{"message": "不支持内省查询", "path": []string{key}})
+			result = append(result, graphQLField{key, nil})
+			continue
+		}
+
+		value, has, err := r.fixture(root.Name, field.Name)
+		if err != nil {
+			r.errors = append(r.errors, gin.H{"message": err.Error(), "path": []string{key}})
+			result = append(result, graphQLField{key, nil})
+			continue
+		}
+		result = append(result, graphQLField{key, r.complete(field.Definition.Type, field, value, has, 0)})
+	}
+	return result
+}
+
+// 读取根字段的fixture，"类型.字段"优先于字段名
+func (r *graphQLResolver) fixture(typeName, fieldName string) (interface{}, bool, error) {
+	name, ok := r.fixtures[typeName+"."+fieldName]
+	if !ok {
+		name, ok = r.fixtures[fieldName]
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	data, err := readProjectFile(r.project, name)
+	if err != nil {
+		return nil, false, fmt.Errorf("读取fixture失败: %v", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false, fmt.Errorf("fixture %s 不是有效的JSON: %v", name, err)
+	}
+	return value, true, nil
+}
+
+func responseKey(field *ast.Field) string {
+	if field.Alias != "" {
+		return field.Alias
+	}
+	return field.Name
+}
+
+// 展开片段并按响应键合并字段，同名字段的子选择集合并
+func (r *graphQLResolver) collectFields(def *ast.Definition, set ast.SelectionSet) []*ast.Field {
+	var fields []*ast.Field
+	index := make(map[string]int)
+	var walk func(set ast.SelectionSet)
+	walk = func(set ast.SelectionSet) {
+		for _, selection := range set {
+			switch s := selection.(type) {
+			case *ast.Field:
+				if r.skipped(s.Directives) {
+					continue
+				}
+				key := responseKey(s)
+				if i, ok := index[key]; ok {
+					merged := *fields[i]
+					merged.SelectionSet = append(append(ast.SelectionSet{}, merged.SelectionSet...), s.SelectionSet...)
+					fields[i] = &merged
+					continue
+				}
+				index[key] = len(fields)
+				fields = append(fields, s)
+			case *ast.InlineFragment:
+				if !r.skipped(s.Directives) && r.typeApplies(def, s.TypeCondition) {
+					walk(s.SelectionSet)
+				}
+			case *ast.FragmentSpread:
+				fragment := r.doc.Fragments.ForName(s.Name)
+				if fragment != nil && !r.skipped(s.Directives) && r.typeApplies(def, fragment.TypeCondition) {
+					walk(fragment.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(set)
+	return fields
+}
+
+func (r *graphQLResolver) skipped(directives ast.DirectiveList) bool {
+	if d := directives.ForName("skip"); d != nil {
+		if skip, _ := d.ArgumentMap(r.vars)["if"].(bool); skip {
+			return true
+		}
+	}
+	if d := directives.ForName("include"); d != nil {
+		if include, ok := d.ArgumentMap(r.vars)["if"].(bool); ok && !include {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *graphQLResolver) typeApplies(def *ast.Definition, condition string) bool {
+	if condition == "" || condition == def.Name {
+		return true
+	}
+	conditionDef := r.schema.Types[condition]
+	if conditionDef == nil || !conditionDef.IsAbstractType() {
+		return false
+	}
+	for _, possible := range r.schema.GetPossibleTypes(conditionDef) {
+		if possible.Name == def.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// 按字段类型补全值：has为true时使用fixture中的值，否则自动生成；
+// index用于让列表中的元素各不相同
+func (r *graphQLResolver) complete(typ *ast.Type, field *ast.Field, value interface{}, has bool, index int) interface{} {
+	if has && value == nil {
+		return nil
+	}
+
+	if typ.Elem != nil {
+		var items []interface{}
+		if has {
+			if list, ok := value.([]interface{}); ok {
+				items = list
+			} else {
+				items = []interface{}{value}
+			}
+		}
+		result := make([]interface{}, 0, r.listSize)
+		if has {
+			for i, item := range items {
+				result = append(result, r.complete(typ.Elem, field, item, true, i))
+			}
+		} else {
+			for i := 0; i < r.listSize; i++ {
+				result = append(result, r.complete(typ.Elem, field, nil, false, i))
+			}
+		}
+		return result
+	}
+
+	def := r.schema.Types[typ.NamedType]
+	if def == nil {
+		return nil
+	}
+	switch def.Kind {
+	case ast.Scalar, ast.Enum:
+		if has {
+			return value
+		}
+		return generateGraphQLScalar(def, field.Name, index)
+	}
+
+	source, _ := value.(map[string]interface{})
+	concrete := def
+	if def.IsAbstractType() {
+		possible := r.schema.GetPossibleTypes(def)
+		if len(possible) == 0 {
+			return nil
+		}
+		concrete = possible[index%len(possible)]
+		if typeName, ok := source["__typename"].(string); ok {
+			for _, p := range possible {
+				if p.Name == typeName {
+					concrete = p
+				}
+			}
+		}
+	}
+	return r.resolveObject(concrete, field.SelectionSet, source, index)
+}
+
+func (r *graphQLResolver) resolveObject(def *ast.Definition, set ast.SelectionSet, source map[string]interface{}, index int) graphQLObject {
+	result := graphQLObject{}
+	for _, field := range r.collectFields(def, set) {
+		key := responseKey(field)
+		if field.Name == "__typename" {
+			result = append(result, graphQLField{key, def.Name})
+			continue
+		}
+		fieldDef := def.Fields.ForName(field.Name)
+		if fieldDef == nil {
+			result = append(result, graphQLField{key, nil})
+			continue
+		}
+		value, has := source[field.Name]
+		result = append(result, graphQLField{key, r.complete(fieldDef.Type, field, value, has, index)})
+	}
+	return result
+}
+
+// 按类型生成示例值，字符串带上字段名和序号便于辨认
+func generateGraphQLScalar(def *ast.Definition, fieldName string, index int) interface{} {
+	n := index + 1
+	if def.Kind == ast.Enum {
+		if len(def.EnumValues) == 0 {
+			return nil
+		}
+		return def.EnumValues[index%len(def.EnumValues)].Name
+	}
+	switch def.Name {
+	case "ID":
+		return strconv.Itoa(n)
+	case "Int":
+		return n
+	case "Float":
+		return float64(n) + 0.5
+	case "Boolean":
+		return n%2 == 1
+	}
+	lower := strings.ToLower(def.Name)
+	if strings.Contains(lower, "date") || strings.Contains(lower, "time") {
+		return time.Now().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s-%d", fieldName, n)
+}
+
+// GraphQL schema文件信息
+type GraphQLSchemaInfo struct {
+	File       string   `json:"file"`
+	Queries    []string `json:"queries"`
+	Mutations  []string `json:"mutations"`
+	ParseError string   `json:"parse_error,omitempty"`
+}
+
+func rootFieldNames(def *ast.Definition) []string {
+	names := []string{}
+	if def == nil {
+		return names
+	}
+	for _, field := range def.Fields {
+		if !strings.HasPrefix(field.Name, "__") {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// API: 列出项目中的GraphQL schema文件及其根字段
+func listGraphQLSchemas(c *gin.Context) {
+	entries, err := os.ReadDir(getGraphQLPath(currentProject))
+	if err != nil && !os.IsNotExist(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	schemas := []GraphQLSchemaInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isGraphQLSchemaFile(entry.Name()) {
+			continue
+		}
+		info := GraphQLSchemaInfo{File: entry.Name(), Queries: []string{}, Mutations: []string{}}
+		schema, err := loadGraphQLSchema(currentProject, entry.Name())
+		if err != nil {
+			info.ParseError = err.Error()
+		} else {
+			info.Queries = rootFieldNames(schema.Query)
+			info.Mutations = rootFieldNames(schema.Mutation)
+		}
+		schemas = append(schemas, info)
+	}
+	c.JSON(http.StatusOK, schemas)
+}
+
+// API: 上传GraphQL schema文件，解析失败时不保存
+func uploadGraphQLSchema(c *gin.Context) {
+	upload, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有上传文件"})
+		return
+	}
+	name := upload.Filename
+	if strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") || !isGraphQLSchemaFile(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法文件名: " + name})
+		return
+	}
+
+	file, err := upload.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := gqlparser.LoadSchema(&ast.Source{Name: name, Input: buf.String()}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "schema解析失败: " + err.Error()})
+		return
+	}
+
+	dir := getGraphQLPath(currentProject)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "上传成功", "file": name})
+}
+
+// API: 删除GraphQL schema文件
+func deleteGraphQLSchema(c *gin.Context) {
+	name := c.Param("name")
+	if strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法文件名"})
+		return
+	}
+	if err := os.Remove(filepath.Join(getGraphQLPath(currentProject), name)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
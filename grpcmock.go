@@ -0,0 +1,487 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/linker"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// gRPC模拟服务配置，在单独的端口上随模拟服务器一起启动
+type GRPCMockConfig struct {
+	Enabled bool             `json:"enabled"`
+	Port    string           `json:"port"`
+	Methods []GRPCMethodMock `json:"methods,omitempty"`
+}
+
+// 方法的模拟响应。ResponseFile为JSON文件目录下的文件，按protobuf JSON格式解析；
+// 服务端流式方法的响应文件为数组，每个元素作为一条消息发送
+type GRPCMethodMock struct {
+	Method           string `json:"method"`
+	ResponseFile     string `json:"response_file,omitempty"`
+	StreamIntervalMs int    `json:"stream_interval_ms,omitempty"`
+	ErrorCode        int    `json:"error_code,omitempty"`
+	ErrorMessage     string `json:"error_message,omitempty"`
+}
+
+// 服务和方法信息，供页面展示
+type GRPCServiceInfo struct {
+	Name    string           `json:"name"`
+	File    string           `json:"file"`
+	Methods []GRPCMethodInfo `json:"methods"`
+}
+
+type GRPCMethodInfo struct {
+	Name            string `json:"name"`
+	FullMethod      string `json:"full_method"`
+	Input           string `json:"input"`
+	Output          string `json:"output"`
+	ClientStreaming bool   `json:"client_streaming"`
+	ServerStreaming bool   `json:"server_streaming"`
+}
+
+func getProtosPath(project string) string {
+	return filepath.Join(getProjectPath(project), "protos")
+}
+
+// 列出项目中的proto文件，返回相对protos目录的路径
+func listProtoFiles(project string) ([]string, error) {
+	root := getProtosPath(project)
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".proto") {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// 编译项目中的全部proto文件，google/protobuf下的标准文件可以直接import
+func compileProtos(project string) (linker.Files, error) {
+	files, err := listProtoFiles(project)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{getProtosPath(project)},
+		}),
+	}
+	return compiler.Compile(context.Background(), files...)
+}
+
+func grpcServiceInfos(files linker.Files) []GRPCServiceInfo {
+	services := []GRPCServiceInfo{}
+	for _, file := range files {
+		for i := 0; i < file.Services().Len(); i++ {
+			svc := file.Services().Get(i)
+			info := GRPCServiceInfo{Name: string(svc.FullName()), File: file.Path()}
+			for j := 0; j < svc.Methods().Len(); j++ {
+				md := svc.Methods().Get(j)
+				info.Methods = append(info.Methods, GRPCMethodInfo{
+					Name:            string(md.Name()),
+					FullMethod:      fmt.Sprintf("%s/%s", svc.FullName(), md.Name()),
+					Input:           string(md.Input().FullName()),
+					Output:          string(md.Output().FullName()),
+					ClientStreaming: md.IsStreamingClient(),
+					ServerStreaming: md.IsStreamingServer(),
+				})
+			}
+			services = append(services, info)
+		}
+	}
+	return services
+}
+
+// 反射服务使用的描述符查找，先查上传的proto，再查全局注册的标准类型
+type grpcDescriptorResolver struct {
+	files *protoregistry.Files
+}
+
+func (r grpcDescriptorResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.files.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r grpcDescriptorResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.files.FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
+
+var _ protodesc.Resolver = grpcDescriptorResolver{}
+
+// 注册文件及其依赖，已注册的文件跳过
+func registerFileWithDeps(registry *protoregistry.Files, fd protoreflect.FileDescriptor) error {
+	if _, err := registry.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if err := registerFileWithDeps(registry, imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return registry.RegisterFile(fd)
+}
+
+type grpcMockServer struct {
+	mu     sync.Mutex
+	server *grpc.Server
+}
+
+var grpcMock = &grpcMockServer{}
+
+// 按proto中的服务注册动态处理函数并启动监听
+func (s *grpcMockServer) start(project, ip string, cfg *GRPCMockConfig) error {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	files, err := compileProtos(project)
+	if err != nil {
+		return fmt.Errorf("编译proto文件失败: %v", err)
+	}
+	if len(files) == 0 {
+		return errors.New("项目中没有proto文件")
+	}
+
+	registry := new(protoregistry.Files)
+	for _, file := range files {
+		if err := registerFileWithDeps(registry, file); err != nil {
+			return fmt.Errorf("注册proto文件失败: %v", err)
+		}
+	}
+
+	mocks := make(map[string]GRPCMethodMock)
+	for _, m := range cfg.Methods {
+		mocks[strings.TrimPrefix(m.Method, "/")] = m
+	}
+
+	srv := grpc.NewServer()
+	for _, file := range files {
+		for i := 0; i < file.Services().Len(); i++ {
+			svc := file.Services().Get(i)
+			desc := &grpc.ServiceDesc{
+				ServiceName: string(svc.FullName()),
+				HandlerType: (*interface{})(nil),
+				Metadata:    file.Path(),
+			}
+			for j := 0; j < svc.Methods().Len(); j++ {
+				md := svc.Methods().Get(j)
+				fullMethod := fmt.Sprintf("%s/%s", svc.FullName(), md.Name())
+				h := &grpcMethodHandler{project: project, fullMethod: fullMethod, method: md, mock: mocks[fullMethod]}
+				if !md.IsStreamingClient() && !md.IsStreamingServer() {
+					desc.Methods = append(desc.Methods, grpc.MethodDesc{MethodName: string(md.Name()), Handler: h.unary})
+				} else {
+					desc.Streams = append(desc.Streams, grpc.StreamDesc{
+						StreamName:    string(md.Name()),
+						Handler:       h.stream,
+						ServerStreams: md.IsStreamingServer(),
+						ClientStreams: md.IsStreamingClient(),
+					})
+				}
+			}
+			srv.RegisterService(desc, struct{}{})
+		}
+	}
+	// 反射服务使用上传proto的描述符
+	opts := reflection.ServerOptions{Services: srv, DescriptorResolver: grpcDescriptorResolver{registry}}
+	reflectionv1.RegisterServerReflectionServer(srv, reflection.NewServerV1(opts))
+	reflectionv1alpha.RegisterServerReflectionServer(srv, reflection.NewServer(opts))
+
+	addr := net.JoinHostPort(ip, cfg.Port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("gRPC监听 %s 启动失败: %v", addr, err)
+	}
+
+	s.mu.Lock()
+	s.server = srv
+	s.mu.Unlock()
+
+	log.Printf("gRPC模拟服务启动在 %s", addr)
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			log.Printf("gRPC模拟服务停止: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *grpcMockServer) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server != nil {
+		s.server.Stop()
+		s.server = nil
+	}
+}
+
+type grpcMethodHandler struct {
+	project    string
+	fullMethod string
+	method     protoreflect.MethodDescriptor
+	mock       GRPCMethodMock
+}
+
+func (h *grpcMethodHandler) log(ctx context.Context, req proto.Message) {
+	headers := make(map[string]interface{})
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, v := range md {
+			headers[k] = v
+		}
+	}
+	body, _ := protojson.Marshal(req)
+	appendRequestLog(RequestLog{
+		ID:        len(server.RequestLogs) + 1,
+		Path:      "/" + h.fullMethod,
+		Method:    "GRPC",
+		Headers:   headers,
+		Body:      string(body),
+		Timestamp: time.Now(),
+		Protocol:  "grpc",
+	})
+}
+
+// 读取响应文件，数组的每个元素为一条消息
+func (h *grpcMethodHandler) responses() ([]proto.Message, error) {
+	if h.mock.ErrorCode != 0 {
+		return nil, status.Error(codes.Code(h.mock.ErrorCode), h.mock.ErrorMessage)
+	}
+	if h.mock.ResponseFile == "" {
+		return nil, nil
+	}
+	data, err := readProjectFile(h.project, h.mock.ResponseFile)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "读取响应文件失败: %v", err)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		items = []json.RawMessage{data}
+	}
+	messages := make([]proto.Message, 0, len(items))
+	for _, item := range items {
+		msg := dynamicpb.NewMessage(h.method.Output())
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(item, msg); err != nil {
+			return nil, status.Errorf(codes.Internal, "响应文件无法转换为 %s: %v", h.method.Output().FullName(), err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+func (h *grpcMethodHandler) unary(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := dynamicpb.NewMessage(h.method.Input())
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	h.log(ctx, req)
+
+	messages, err := h.responses()
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return dynamicpb.NewMessage(h.method.Output()), nil
+	}
+	return messages[0], nil
+}
+
+// 按间隔依次发送，客户端断开时停止
+func (h *grpcMethodHandler) sendAll(stream grpc.ServerStream, messages []proto.Message) error {
+	for i, msg := range messages {
+		if i > 0 && h.mock.StreamIntervalMs > 0 {
+			select {
+			case <-time.After(time.Duration(h.mock.StreamIntervalMs) * time.Millisecond):
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			}
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 流式方法：服务端流回复列表；客户端流收完后回复一条；双向流对每条请求回复列表
+func (h *grpcMethodHandler) stream(_ interface{}, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	for {
+		req := dynamicpb.NewMessage(h.method.Input())
+		err := stream.RecvMsg(req)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		h.log(ctx, req)
+
+		if h.method.IsStreamingClient() && !h.method.IsStreamingServer() {
+			continue
+		}
+		messages, err := h.responses()
+		if err != nil {
+			return err
+		}
+		if err := h.sendAll(stream, messages); err != nil {
+			return err
+		}
+		if !h.method.IsStreamingClient() {
+			return nil
+		}
+	}
+
+	if h.method.IsStreamingServer() {
+		return nil
+	}
+	messages, err := h.responses()
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return stream.SendMsg(dynamicpb.NewMessage(h.method.Output()))
+	}
+	return stream.SendMsg(messages[0])
+}
+
+// API: 列出项目中的proto文件和服务
+func listProtos(c *gin.Context) {
+	files, err := listProtoFiles(currentProject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if files == nil {
+		files = []string{}
+	}
+	response := gin.H{"files": files, "services": []GRPCServiceInfo{}}
+	compiled, err := compileProtos(currentProject)
+	if err != nil {
+		response["compile_error"] = err.Error()
+	} else {
+		response["services"] = grpcServiceInfos(compiled)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// API: 上传proto文件，编译失败时撤销本次上传
+func uploadProtos(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	uploads := form.File["file"]
+	if len(uploads) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有上传文件"})
+		return
+	}
+
+	dir := getProtosPath(currentProject)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	backups := make(map[string][]byte)
+	var saved []string
+	restore := func() {
+		for _, path := range saved {
+			if data, ok := backups[path]; ok {
+				os.WriteFile(path, data, 0644)
+			} else {
+				os.Remove(path)
+			}
+		}
+	}
+
+	for _, upload := range uploads {
+		name := upload.Filename
+		if strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") || !strings.HasSuffix(name, ".proto") {
+			restore()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "非法文件名: " + name})
+			return
+		}
+		path := filepath.Join(dir, name)
+		if data, err := os.ReadFile(path); err == nil {
+			backups[path] = data
+		}
+		if err := c.SaveUploadedFile(upload, path); err != nil {
+			restore()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败: " + err.Error()})
+			return
+		}
+		saved = append(saved, path)
+	}
+
+	compiled, err := compileProtos(currentProject)
+	if err != nil {
+		restore()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "proto编译失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "上传成功", "services": grpcServiceInfos(compiled)})
+}
+
+// API: 删除proto文件
+func deleteProto(c *gin.Context) {
+	name := c.Param("name")
+	if strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法文件名"})
+		return
+	}
+	if err := os.Remove(filepath.Join(getProtosPath(currentProject), name)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 每个项目最多保留的发送记录数
+const maxSendHistory = 2000
+
+// 发送记录来源
+const (
+	sendSourceManual = "manual"
+	sendSourceJob    = "job"
+	sendSourceResend = "resend"
+)
+
+// 一次发送的完整记录
+type SendHistoryEntry struct {
+	ID        int         `json:"id"`
+	Timestamp time.Time   `json:"timestamp"`
+	Source    string      `json:"source"`
+	Request   SendRequest `json:"request"`
+	Result    *SendResult `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// 列表中展示的摘要
+type SendHistorySummary struct {
+	ID        int       `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	Status    int       `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	TotalMs   float64   `json:"total_ms"`
+}
+
+type sendHistoryStore struct {
+	mu      sync.Mutex
+	entries map[string][]SendHistoryEntry
+	nextID  map[string]int
+}
+
+var sendHistory = &sendHistoryStore{
+	entries: make(map[string][]SendHistoryEntry),
+	nextID:  make(map[string]int),
+}
+
+func getSendHistoryPath(project string) string {
+	return filepath.Join(getProjectPath(project), "send_history.jsonl")
+}
+
+// 按需加载项目的发送记录，调用方需持有s.mu
+func (s *sendHistoryStore) load(project string) []SendHistoryEntry {
+	if entries, ok := s.entries[project]; ok {
+		return entries
+	}
+
+	entries := []SendHistoryEntry{}
+	maxID := 0
+	if file, err := os.Open(getSendHistoryPath(project)); err == nil {
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+		for scanner.Scan() {
+			var entry SendHistoryEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+			if entry.ID > maxID {
+				maxID = entry.ID
+			}
+		}
+		file.Close()
+	}
+
+	s.entries[project] = entries
+	s.nextID[project] = maxID
+	return entries
+}
+
+// 丢弃项目的缓存，项目删除或重命名后使用
+func (s *sendHistoryStore) forget(project string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, project)
+	delete(s.nextID, project)
+}
+
+// 调用方需持有s.mu
+func (s *sendHistoryStore) rewrite(project string) error {
+	var buf bytes.Buffer
+	for _, entry := range s.entries[project] {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(getSendHistoryPath(project), buf.Bytes(), 0644)
+}
+
+// 追加一条发送记录，超过上限时裁剪最早的记录
+func (s *sendHistoryStore) record(project, source string, req SendRequest, result *SendResult, sendErr error) SendHistoryEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.load(project)
+	s.nextID[project]++
+	entry := SendHistoryEntry{
+		ID:        s.nextID[project],
+		Timestamp: time.Now(),
+		Source:    source,
+		Request:   req,
+		Result:    result,
+	}
+	if sendErr != nil {
+		entry.Error = sendErr.Error()
+	}
+	entries = append(entries, entry)
+
+	if len(entries) > maxSendHistory {
+		s.entries[project] = entries[len(entries)-maxSendHistory:]
+		if err := s.rewrite(project); err != nil {
+			log.Printf("保存发送记录失败: %v", err)
+		}
+		return entry
+	}
+	s.entries[project] = entries
+
+	line, err := json.Marshal(entry)
+	if err == nil {
+		var file *os.File
+		file, err = os.OpenFile(getSendHistoryPath(project), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.Write(append(line, '\n'))
+			file.Close()
+		}
+	}
+	if err != nil {
+		log.Printf("保存发送记录失败: %v", err)
+	}
+	return entry
+}
+
+func (s *sendHistoryStore) find(project string, id int) (SendHistoryEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.load(project) {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return SendHistoryEntry{}, false
+}
+
+func (entry SendHistoryEntry) summary() SendHistorySummary {
+	summary := SendHistorySummary{
+		ID:        entry.ID,
+		Timestamp: entry.Timestamp,
+		Source:    entry.Source,
+		Method:    entry.Request.Method,
+		URL:       entry.Request.URL,
+		Error:     entry.Error,
+	}
+	if entry.Result != nil {
+		summary.Status = entry.Result.Status
+		summary.TotalMs = entry.Result.Timings.TotalMs
+	}
+	return summary
+}
+
+// 关键字匹配URL、请求数据、原始请求和响应体
+func (entry SendHistoryEntry) matches(keyword string) bool {
+	if keyword == "" {
+		return true
+	}
+	fields := []string{entry.Request.URL, entry.Request.Data, entry.Error}
+	if entry.Result != nil {
+		fields = append(fields, entry.Result.Body, entry.Result.RawRequest)
+	}
+	for _, field := range fields {
+		if strings.Contains(field, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func historyIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		id, err = strconv.Atoi(c.Query(name))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法记录ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// API: 查询发送记录，支持关键字、方法、状态码过滤和分页，按时间倒序
+func listSendHistory(c *gin.Context) {
+	keyword := c.Query("q")
+	method := strings.ToUpper(c.Query("method"))
+	status, _ := strconv.Atoi(c.Query("status"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	sendHistory.mu.Lock()
+	entries := sendHistory.load(currentProject)
+	var matched []SendHistorySummary
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if method != "" && strings.ToUpper(entry.Request.Method) != method {
+			continue
+		}
+		if status != 0 && (entry.Result == nil || entry.Result.Status != status) {
+			continue
+		}
+		if !entry.matches(keyword) {
+			continue
+		}
+		matched = append(matched, entry.summary())
+	}
+	sendHistory.mu.Unlock()
+
+	total := len(matched)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit < total-offset {
+		end = offset + limit
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":   total,
+		"entries": append([]SendHistorySummary{}, matched[offset:end]...),
+	})
+}
+
+// API: 查看单条发送记录
+func getSendHistoryEntry(c *gin.Context) {
+	id, ok := historyIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entry, found := sendHistory.find(currentProject, id)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "记录不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// API: 按记录重新发送，认证签名等会重新计算
+func resendHistoryEntry(c *gin.Context) {
+	id, ok := historyIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entry, found := sendHistory.find(currentProject, id)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "记录不存在"})
+		return
+	}
+
+	client, err := buildHTTPClient(currentProject, effectiveTransport(currentProject, entry.Request.Transport), 2)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := executeSendRequest(client, currentProject, entry.Request, true)
+	newEntry := sendHistory.record(currentProject, sendSourceResend, entry.Request, result, err)
+
+	c.JSON(http.StatusOK, newEntry)
+}
+
+// API: 清空当前项目的发送记录
+func clearSendHistory(c *gin.Context) {
+	sendHistory.mu.Lock()
+	defer sendHistory.mu.Unlock()
+
+	sendHistory.entries[currentProject] = []SendHistoryEntry{}
+	if err := os.Remove(getSendHistoryPath(currentProject)); err != nil && !os.IsNotExist(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "发送记录已清空"})
+}
+
+// 差异行，Op为" "、"-"、"+"
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type HeaderDiff struct {
+	Name string   `json:"name"`
+	A    []string `json:"a,omitempty"`
+	B    []string `json:"b,omitempty"`
+}
+
+// API: 对比两条发送记录
+func diffSendHistory(c *gin.Context) {
+	idA, ok := historyIDParam(c, "a")
+	if !ok {
+		return
+	}
+	idB, ok := historyIDParam(c, "b")
+	if !ok {
+		return
+	}
+
+	a, foundA := sendHistory.find(currentProject, idA)
+	b, foundB := sendHistory.find(currentProject, idB)
+	if !foundA || !foundB {
+		c.JSON(http.StatusNotFound, gin.H{"error": "记录不存在"})
+		return
+	}
+
+	var resultA, resultB SendResult
+	if a.Result != nil {
+		resultA = *a.Result
+	}
+	if b.Result != nil {
+		resultB = *b.Result
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"a":           a.summary(),
+		"b":           b.summary(),
+		"status_same": resultA.Status == resultB.Status,
+		"request":     diffLines(prettyBody(a.Request.Data), prettyBody(b.Request.Data)),
+		"raw_request": diffLines(resultA.RawRequest, resultB.RawRequest),
+		"headers":     diffHeaders(resultA.Headers, resultB.Headers),
+		"body":        diffLines(prettyBody(resultA.Body), prettyBody(resultB.Body)),
+	})
+}
+
+// JSON内容按缩进格式展开后再比较，便于逐行对比
+func prettyBody(body string) string {
+	var data interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&data) != nil {
+		return body
+	}
+	pretty, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return body
+	}
+	return string(pretty)
+}
+
+func diffHeaders(a, b map[string][]string) []HeaderDiff {
+	names := make(map[string]bool)
+	for k := range a {
+		names[k] = true
+	}
+	for k := range b {
+		names[k] = true
+	}
+
+	diffs := []HeaderDiff{}
+	for name := range names {
+		if strings.Join(a[name], "\n") != strings.Join(b[name], "\n") {
+			diffs = append(diffs, HeaderDiff{Name: name, A: a[name], B: b[name]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+// 基于最长公共子序列的逐行对比
+func diffLines(a, b string) []DiffLine {
+	if a == b {
+		return []DiffLine{}
+	}
+	la := strings.Split(strings.ReplaceAll(a, "\r\n", "\n"), "\n")
+	lb := strings.Split(strings.ReplaceAll(b, "\r\n", "\n"), "\n")
+
+	// 行数过多时退化为整体替换，避免占用过多内存
+	if len(la)*len(lb) > 4000000 {
+		var lines []DiffLine
+		for _, line := range la {
+			lines = append(lines, DiffLine{Op: "-", Text: line})
+		}
+		for _, line := range lb {
+			lines = append(lines, DiffLine{Op: "+", Text: line})
+		}
+		return lines
+	}
+
+	lcs := make([][]int, len(la)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(lb)+1)
+	}
+	for i := len(la) - 1; i >= 0; i-- {
+		for j := len(lb) - 1; j >= 0; j-- {
+			if la[i] == lb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []DiffLine
+	i, j := 0, 0
+	for i < len(la) && j < len(lb) {
+		switch {
+		case la[i] == lb[j]:
+			lines = append(lines, DiffLine{Op: " ", Text: la[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: "-", Text: la[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: "+", Text: lb[j]})
+			j++
+		}
+	}
+	for ; i < len(la); i++ {
+		lines = append(lines, DiffLine{Op: "-", Text: la[i]})
+	}
+	for ; j < len(lb); j++ {
+		lines = append(lines, DiffLine{Op: "+", Text: lb[j]})
+	}
+	return lines
+}
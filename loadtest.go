@@ -0,0 +1,474 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 压测配置
+type LoadTestConfig struct {
+	Block           SendBlock `json:"block"`
+	Data            string    `json:"data"`
+	Concurrency     int       `json:"concurrency"`
+	TotalRequests   int       `json:"total_requests"`
+	DurationSeconds int       `json:"duration_seconds"`
+	StartRPS        float64   `json:"start_rps"`
+	TargetRPS       float64   `json:"target_rps"`
+	RampSeconds     int       `json:"ramp_seconds"`
+}
+
+// 压测进度快照
+type LoadTestSnapshot struct {
+	Running     bool             `json:"running"`
+	Config      LoadTestConfig   `json:"config"`
+	StartedAt   time.Time        `json:"started_at"`
+	Elapsed     float64          `json:"elapsed_seconds"`
+	Sent        int64            `json:"sent"`
+	Completed   int64            `json:"completed"`
+	Failed      int64            `json:"failed"`
+	CurrentRPS  float64          `json:"current_rps"`
+	ActualRPS   float64          `json:"actual_rps"`
+	Latency     LatencySummary   `json:"latency"`
+	StatusCodes map[string]int64 `json:"status_codes"`
+	Errors      map[string]int64 `json:"errors"`
+}
+
+type LatencySummary struct {
+	Min       float64          `json:"min_ms"`
+	Max       float64          `json:"max_ms"`
+	Mean      float64          `json:"mean_ms"`
+	P50       float64          `json:"p50_ms"`
+	P90       float64          `json:"p90_ms"`
+	P99       float64          `json:"p99_ms"`
+	Histogram []HistogramEntry `json:"histogram"`
+}
+
+type HistogramEntry struct {
+	UpperMs float64 `json:"le_ms"`
+	Count   int64   `json:"count"`
+}
+
+// 延迟直方图，按指数增长的桶统计，避免保存全部样本
+type latencyHistogram struct {
+	counts []int64
+	total  int64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+const (
+	histogramGrowth  = 1.1
+	histogramBuckets = 200
+)
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]int64, histogramBuckets)}
+}
+
+func histogramBucketUpper(i int) time.Duration {
+	return time.Duration(math.Pow(histogramGrowth, float64(i)) * float64(time.Microsecond))
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	us := float64(d / time.Microsecond)
+	idx := 0
+	if us > 1 {
+		idx = int(math.Ceil(math.Log(us) / math.Log(histogramGrowth)))
+	}
+	if idx >= len(h.counts) {
+		idx = len(h.counts) - 1
+	}
+	h.counts[idx]++
+	h.total++
+	h.sum += d
+	if h.min == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+}
+
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(float64(h.total) * p))
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= target {
+			upper := histogramBucketUpper(i)
+			if upper > h.max {
+				return h.max
+			}
+			return upper
+		}
+	}
+	return h.max
+}
+
+func (h *latencyHistogram) summary() LatencySummary {
+	ms := func(d time.Duration) float64 {
+		return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+	}
+	s := LatencySummary{
+		Min: ms(h.min),
+		Max: ms(h.max),
+		P50: ms(h.percentile(0.50)),
+		P90: ms(h.percentile(0.90)),
+		P99: ms(h.percentile(0.99)),
+	}
+	if h.total > 0 {
+		s.Mean = ms(h.sum / time.Duration(h.total))
+	}
+	for i, n := range h.counts {
+		if n > 0 {
+			s.Histogram = append(s.Histogram, HistogramEntry{UpperMs: ms(histogramBucketUpper(i)), Count: n})
+		}
+	}
+	return s
+}
+
+// 一次压测运行
+type loadTestRun struct {
+	config    LoadTestConfig
+	project   string
+	request   SendRequest
+	client    *http.Client
+	startedAt time.Time
+	stop      chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+
+	sent      int64
+	completed int64
+	failed    int64
+
+	mu          sync.Mutex
+	histogram   *latencyHistogram
+	statusCodes map[string]int64
+	errorTypes  map[string]int64
+	finishedAt  time.Time
+}
+
+var (
+	loadTestMu      sync.Mutex
+	currentLoadTest *loadTestRun
+)
+
+// 根据发送块构造发送请求，请求体优先使用传入的数据，否则读取项目中的发送文件
+func buildSendRequestFromBlock(project string, block SendBlock, data string) (SendRequest, error) {
+	req := SendRequest{
+		URL:        block.URL,
+		Method:     block.Method,
+		Headers:    map[string]string{},
+		Data:       data,
+		BodyType:   block.BodyType,
+		FormFields: block.FormFields,
+		BodyFile:   block.BodyFile,
+		Auth:       block.Auth,
+		Transport:  block.Transport,
+	}
+	if req.Method == "" {
+		req.Method = http.MethodPost
+	}
+	if req.URL == "" {
+		return req, errors.New("请求URL不能为空")
+	}
+
+	if strings.TrimSpace(block.Headers) != "" {
+		if err := json.Unmarshal([]byte(block.Headers), &req.Headers); err != nil {
+			return req, fmt.Errorf("请求头格式错误: %v", err)
+		}
+	}
+
+	if req.Data == "" && block.SendFile != "" && (req.BodyType == "" || req.BodyType == bodyTypeRaw) {
+		content, err := readProjectFile(project, block.SendFile)
+		if err != nil {
+			return req, fmt.Errorf("读取发送文件失败: %v", err)
+		}
+		req.Data = string(content)
+	}
+
+	return req, nil
+}
+
+// 错误分类，便于统计
+func classifyError(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return "dns"
+	}
+	var urlErr *url.Error
+	msg := err.Error()
+	if errors.As(err, &urlErr) {
+		msg = urlErr.Err.Error()
+	}
+	switch {
+	case strings.Contains(msg, "connection refused"):
+		return "connection_refused"
+	case strings.Contains(msg, "connection reset"):
+		return "connection_reset"
+	case strings.Contains(msg, "EOF"):
+		return "eof"
+	case strings.Contains(msg, "tls") || strings.Contains(msg, "x509"):
+		return "tls"
+	default:
+		return "other"
+	}
+}
+
+// 当前时刻的目标RPS，在爬坡时间内从起始RPS线性增长到目标RPS，0表示不限速
+func (run *loadTestRun) rpsAt(elapsed time.Duration) float64 {
+	cfg := run.config
+	if cfg.TargetRPS <= 0 {
+		return 0
+	}
+	if cfg.RampSeconds <= 0 || elapsed >= time.Duration(cfg.RampSeconds)*time.Second {
+		return cfg.TargetRPS
+	}
+	progress := elapsed.Seconds() / float64(cfg.RampSeconds)
+	// 爬坡阶段至少保持1 RPS，避免起始值为0时变成不限速
+	return math.Max(cfg.StartRPS+(cfg.TargetRPS-cfg.StartRPS)*progress, 1)
+}
+
+func (run *loadTestRun) shouldStop() bool {
+	select {
+	case <-run.stop:
+		return true
+	default:
+	}
+	if run.config.DurationSeconds > 0 && time.Since(run.startedAt) >= time.Duration(run.config.DurationSeconds)*time.Second {
+		return true
+	}
+	if run.config.TotalRequests > 0 && atomic.LoadInt64(&run.sent) >= int64(run.config.TotalRequests) {
+		return true
+	}
+	return false
+}
+
+// 调度器：按当前RPS向工作协程派发任务
+func (run *loadTestRun) dispatch(jobs chan<- struct{}) {
+	defer close(jobs)
+
+	next := time.Now()
+	for !run.shouldStop() {
+		rps := run.rpsAt(time.Since(run.startedAt))
+		if rps > 0 {
+			if wait := time.Until(next); wait > 0 {
+				select {
+				case <-run.stop:
+					return
+				case <-time.After(wait):
+				}
+			}
+			next = next.Add(time.Duration(float64(time.Second) / rps))
+			// 落后太多时不补发，避免瞬间突发
+			if lag := time.Since(next); lag > time.Second {
+				next = time.Now()
+			}
+		}
+
+		select {
+		case <-run.stop:
+			return
+		case jobs <- struct{}{}:
+			atomic.AddInt64(&run.sent, 1)
+		}
+	}
+}
+
+func (run *loadTestRun) worker(jobs <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for range jobs {
+		start := time.Now()
+		result, err := executeSendRequest(run.client, run.project, run.request, false)
+		latency := time.Since(start)
+
+		run.mu.Lock()
+		if err != nil {
+			atomic.AddInt64(&run.failed, 1)
+			run.errorTypes[classifyError(err)]++
+		} else {
+			run.histogram.record(latency)
+			run.statusCodes[fmt.Sprintf("%d", result.Status)]++
+		}
+		run.mu.Unlock()
+		atomic.AddInt64(&run.completed, 1)
+	}
+}
+
+func (run *loadTestRun) snapshot() LoadTestSnapshot {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	end := time.Now()
+	running := run.finishedAt.IsZero()
+	if !running {
+		end = run.finishedAt
+	}
+	elapsed := end.Sub(run.startedAt)
+
+	snap := LoadTestSnapshot{
+		Running:     running,
+		Config:      run.config,
+		StartedAt:   run.startedAt,
+		Elapsed:     math.Round(elapsed.Seconds()*100) / 100,
+		Sent:        atomic.LoadInt64(&run.sent),
+		Completed:   atomic.LoadInt64(&run.completed),
+		Failed:      atomic.LoadInt64(&run.failed),
+		CurrentRPS:  run.rpsAt(elapsed),
+		Latency:     run.histogram.summary(),
+		StatusCodes: make(map[string]int64, len(run.statusCodes)),
+		Errors:      make(map[string]int64, len(run.errorTypes)),
+	}
+	if elapsed > 0 {
+		snap.ActualRPS = math.Round(float64(snap.Completed)/elapsed.Seconds()*100) / 100
+	}
+	for k, v := range run.statusCodes {
+		snap.StatusCodes[k] = v
+	}
+	for k, v := range run.errorTypes {
+		snap.Errors[k] = v
+	}
+	return snap
+}
+
+func (run *loadTestRun) execute() {
+	jobs := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < run.config.Concurrency; i++ {
+		wg.Add(1)
+		go run.worker(jobs, &wg)
+	}
+
+	// 每秒推送一次进度
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	go func() {
+		for {
+			select {
+			case <-run.done:
+				return
+			case <-ticker.C:
+				broadcastToClients(map[string]interface{}{"type": "loadtest_progress", "data": run.snapshot()})
+			}
+		}
+	}()
+
+	run.dispatch(jobs)
+	wg.Wait()
+
+	run.mu.Lock()
+	run.finishedAt = time.Now()
+	run.mu.Unlock()
+	close(run.done)
+
+	broadcastToClients(map[string]interface{}{"type": "loadtest_done", "data": run.snapshot()})
+	log.Printf("压测结束: 发送 %d, 完成 %d, 失败 %d", atomic.LoadInt64(&run.sent), atomic.LoadInt64(&run.completed), atomic.LoadInt64(&run.failed))
+}
+
+// API: 启动压测
+func startLoadTest(c *gin.Context) {
+	var config LoadTestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.TotalRequests <= 0 && config.DurationSeconds <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请指定请求总数或持续时间"})
+		return
+	}
+	if config.StartRPS < 0 || config.TargetRPS < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "RPS不能为负数"})
+		return
+	}
+
+	req, err := buildSendRequestFromBlock(currentProject, config.Block, config.Data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loadTestMu.Lock()
+	defer loadTestMu.Unlock()
+
+	if currentLoadTest != nil && currentLoadTest.snapshot().Running {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "已有压测正在运行"})
+		return
+	}
+
+	client, err := buildHTTPClient(currentProject, effectiveTransport(currentProject, req.Transport), config.Concurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run := &loadTestRun{
+		config:      config,
+		project:     currentProject,
+		request:     req,
+		client:      client,
+		startedAt:   time.Now(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		histogram:   newLatencyHistogram(),
+		statusCodes: make(map[string]int64),
+		errorTypes:  make(map[string]int64),
+	}
+	currentLoadTest = run
+	go run.execute()
+
+	c.JSON(http.StatusOK, gin.H{"message": "压测已启动"})
+}
+
+// API: 停止压测
+func stopLoadTest(c *gin.Context) {
+	loadTestMu.Lock()
+	run := currentLoadTest
+	loadTestMu.Unlock()
+
+	if run == nil || !run.snapshot().Running {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有正在运行的压测"})
+		return
+	}
+
+	run.stopOnce.Do(func() { close(run.stop) })
+	c.JSON(http.StatusOK, gin.H{"message": "压测正在停止"})
+}
+
+// API: 查询压测状态
+func getLoadTestStatus(c *gin.Context) {
+	loadTestMu.Lock()
+	run := currentLoadTest
+	loadTestMu.Unlock()
+
+	if run == nil {
+		c.JSON(http.StatusOK, gin.H{"running": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, run.snapshot())
+}
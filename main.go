@@ -1,11 +1,13 @@
 package main
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,17 +25,33 @@ type Server struct {
 	Endpoints         []EndpointConfig       `json:"endpoints"`
 	SendBlocks        []SendBlock            `json:"send_blocks"`
 	RequestLogs       []RequestLog           `json:"request_logs"`
+	SendTransport     *TransportConfig       `json:"send_transport"`
+	TLS               *ListenerTLSConfig     `json:"tls"`
+	H2C               bool                   `json:"h2c"`
+	Listeners         []RawListenerConfig    `json:"listeners"`
+	GRPC              *GRPCMockConfig        `json:"grpc"`
 	mu                sync.RWMutex
 	engine            *gin.Engine
+	httpServer        *http.Server
 	upgrader          websocket.Upgrader
 	clients           map[*websocket.Conn]bool
 	clientsMu         sync.RWMutex
 }
 
 type EndpointConfig struct {
-	Name         string `json:"name"`
-	Path         string `json:"path"`
-	ResponseFile string `json:"response_file"`
+	Name         string              `json:"name"`
+	Path         string              `json:"path"`
+	ResponseFile string              `json:"response_file"`
+	Type         string              `json:"type,omitempty"`
+	WebSocket    *WSMockConfig       `json:"websocket,omitempty"`
+	Stream       *StreamConfig       `json:"stream,omitempty"`
+	GraphQL      *GraphQLConfig      `json:"graphql,omitempty"`
+	SOAP         *SOAPConfig         `json:"soap,omitempty"`
+	File         *FileResponseConfig `json:"file,omitempty"`
+	Fake         *FakeResponseConfig `json:"fake,omitempty"`
+	Resource     *ResourceConfig     `json:"resource,omitempty"`
+	// 请求体的JSON Schema校验，仅HTTP接口
+	RequestSchema *RequestSchemaConfig `json:"request_schema,omitempty"`
 }
 
 type RequestLog struct {
@@ -43,35 +61,72 @@ type RequestLog struct {
 	Headers   map[string]interface{} `json:"headers"`
 	Body      string                 `json:"body"`
 	Timestamp time.Time              `json:"timestamp"`
+	// 协商的协议：h2、h2c、HTTP/1.1
+	Protocol string `json:"protocol"`
+	// 开启双向TLS时记录客户端证书主题
+	ClientCertSubject string `json:"client_cert_subject,omitempty"`
+	// GraphQL接口记录操作名和变量
+	GraphQL *GraphQLRequestInfo `json:"graphql,omitempty"`
+	// 配置了请求体Schema时记录校验结果
+	SchemaValidation *SchemaValidationResult `json:"schema_validation,omitempty"`
 }
 
 type SendRequest struct {
-	URL     string            `json:"url"`
-	Method  string            `json:"method"`
-	Headers map[string]string `json:"headers"`
-	Data    string            `json:"data"`
+	URL        string            `json:"url"`
+	Method     string            `json:"method"`
+	Headers    map[string]string `json:"headers"`
+	Data       string            `json:"data"`
+	BodyType   string            `json:"body_type"`
+	FormFields []FormField       `json:"form_fields"`
+	BodyFile   string            `json:"body_file"`
+	Auth       *AuthConfig       `json:"auth"`
+	Transport  *TransportConfig  `json:"transport"`
+}
+
+type SendResult struct {
+	Status     int                 `json:"status"`
+	Proto      string              `json:"proto"`
+	Headers    map[string][]string `json:"headers"`
+	Body       string              `json:"body"`
+	FinalURL   string              `json:"final_url"`
+	Redirects  []RedirectHop       `json:"redirects"`
+	TLS        *TLSInfo            `json:"tls,omitempty"`
+	Timings    SendTimings         `json:"timings"`
+	RawRequest string              `json:"raw_request"`
 }
 
 type Config struct {
-	IP             string           `json:"ip"`
-	Port           string           `json:"port"`
-	CurrentProject string           `json:"current_project"`
-	Endpoints      []EndpointConfig `json:"endpoints"`
-	SendBlocks     []SendBlock      `json:"send_blocks"`
+	IP             string              `json:"ip"`
+	Port           string              `json:"port"`
+	CurrentProject string              `json:"current_project"`
+	Endpoints      []EndpointConfig    `json:"endpoints"`
+	SendBlocks     []SendBlock         `json:"send_blocks"`
+	SendTransport  *TransportConfig    `json:"send_transport,omitempty"`
+	TLS            *ListenerTLSConfig  `json:"tls,omitempty"`
+	H2C            bool                `json:"h2c,omitempty"`
+	Listeners      []RawListenerConfig `json:"listeners,omitempty"`
+	GRPC           *GRPCMockConfig     `json:"grpc,omitempty"`
 }
 
 type SendBlock struct {
-	Name     string `json:"name"`
-	URL      string `json:"url"`
-	SendFile string `json:"send_file"`
-	Method   string `json:"method"`
-	Headers  string `json:"headers"`
+	Name       string           `json:"name"`
+	URL        string           `json:"url"`
+	SendFile   string           `json:"send_file"`
+	Method     string           `json:"method"`
+	Headers    string           `json:"headers"`
+	BodyType   string           `json:"body_type,omitempty"`
+	FormFields []FormField      `json:"form_fields,omitempty"`
+	BodyFile   string           `json:"body_file,omitempty"`
+	Auth       *AuthConfig      `json:"auth,omitempty"`
+	Transport  *TransportConfig `json:"transport,omitempty"`
 }
 
 type ProjectInfo struct {
-	Name      string `json:"name"`
-	Path      string `json:"path"`
-	CreatedAt string `json:"created_at"`
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	CreatedAt  string `json:"created_at"`
+	Archived   bool   `json:"archived"`
+	ArchivedAt string `json:"archived_at,omitempty"`
 }
 
 var server *Server
@@ -119,6 +174,11 @@ func main() {
 		log.Printf("加载项目配置文件失败: %v", err)
 	}
 
+	// 加载定时任务
+	if err := initScheduler(); err != nil {
+		log.Printf("加载定时任务失败: %v", err)
+	}
+
 	createHTMLTemplate()
 	createCSSFile()
 	createJSFile()
@@ -149,11 +209,64 @@ func main() {
 		api.GET("/logs", getLogs)
 		api.POST("/send", sendRequest)
 		api.GET("/files", listJSONFiles)
+		api.DELETE("/files", deleteProjectFile)
+		api.POST("/files/rename", renameProjectFile)
+		api.POST("/files/duplicate", duplicateProjectFile)
+		api.POST("/files/mkdir", createProjectFolder)
+		api.POST("/files/upload", uploadProjectFiles)
+		api.GET("/files/download", downloadProjectFile)
 		api.POST("/save-json", saveJSONFile)
 		api.GET("/read-json", readJSONFile)
 		api.GET("/projects", listProjects)
 		api.POST("/projects", createProject)
+		api.DELETE("/projects/:name", deleteProject)
+		api.POST("/projects/:name/rename", renameProject)
+		api.POST("/projects/:name/clone", cloneProject)
+		api.POST("/projects/:name/archive", archiveProject)
+		api.POST("/projects/:name/unarchive", unarchiveProject)
+		api.GET("/projects/:name/export", exportProject)
+		api.POST("/projects/import", importProject)
+		api.GET("/project-templates", listProjectTemplates)
+		api.POST("/fake-data", generateFakeFixture)
+		api.GET("/fake-data/infer", inferFakeSchema)
+		api.GET("/resources", listResources)
+		api.POST("/resources/reset", resetResources)
 		api.POST("/switch-project", switchProject)
+		api.POST("/loadtest/start", startLoadTest)
+		api.POST("/loadtest/stop", stopLoadTest)
+		api.GET("/loadtest/status", getLoadTestStatus)
+		api.GET("/jobs", listJobs)
+		api.POST("/jobs", createJob)
+		api.PUT("/jobs/:id", updateJob)
+		api.DELETE("/jobs/:id", deleteJob)
+		api.POST("/jobs/:id/start", startJobHandler)
+		api.POST("/jobs/:id/pause", pauseJob)
+		api.POST("/jobs/:id/stop", stopJob)
+		api.GET("/jobs/:id/history", getJobHistory)
+		api.GET("/job-history", getJobHistory)
+		api.GET("/send-history", listSendHistory)
+		api.DELETE("/send-history", clearSendHistory)
+		api.GET("/send-history/diff", diffSendHistory)
+		api.GET("/send-history/:id", getSendHistoryEntry)
+		api.GET("/tls/ca", downloadCACert)
+		api.GET("/ws-mock/connections", listWSMockConnections)
+		api.POST("/ws-mock/push", pushWSMockMessage)
+		api.GET("/grpc/protos", listProtos)
+		api.POST("/grpc/protos", uploadProtos)
+		api.DELETE("/grpc/protos/:name", deleteProto)
+		api.GET("/graphql/schemas", listGraphQLSchemas)
+		api.POST("/graphql/schemas", uploadGraphQLSchema)
+		api.DELETE("/graphql/schemas/:name", deleteGraphQLSchema)
+		api.GET("/stream-client", listStreamClients)
+		api.POST("/stream-client", connectStreamClient)
+		api.POST("/stream-client/:id/send", sendStreamClientMessage)
+		api.DELETE("/stream-client/:id", closeStreamClient)
+		api.POST("/send-history/:id/resend", resendHistoryEntry)
+		api.GET("/revisions", listRevisions)
+		api.GET("/revisions/diff", diffRevisions)
+		api.GET("/revisions/:id", getRevision)
+		api.POST("/revisions/:id/restore", restoreRevision)
+		api.POST("/revisions/restore-project", restoreProjectRevision)
 	}
 
 	log.Println("HTTP+JSON工具启动在 http://localhost:8080")
@@ -231,6 +344,11 @@ func getStatus(c *gin.Context) {
 		"endpoints":       server.Endpoints,
 		"send_blocks":     server.SendBlocks,
 		"request_logs":    server.RequestLogs,
+		"send_transport":  server.SendTransport,
+		"tls":             server.TLS,
+		"h2c":             server.H2C,
+		"listeners":       server.Listeners,
+		"grpc":            server.GRPC,
 		"current_project": currentProject,
 	}
 
@@ -246,32 +364,118 @@ func startServer(c *gin.Context) {
 		return
 	}
 
+	addr := fmt.Sprintf("%s:%s", server.IP, server.Port)
+	httpServer := &http.Server{Addr: addr}
+	scheme := "http"
+	if server.TLS != nil && server.TLS.Enabled {
+		tlsConfig, err := buildListenerTLSConfig(currentProject, server.IP, server.TLS)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "TLS配置错误: " + err.Error()})
+			return
+		}
+		httpServer.TLSConfig = tlsConfig
+		if server.TLS.DisableHTTP2 {
+			// 非nil的空映射会关闭ALPN协商HTTP/2
+			httpServer.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		}
+		scheme = "https"
+	}
+
+	// TCP/UDP监听和gRPC服务与HTTP服务一起启动
+	if err := rawListeners.start(currentProject, server.IP, server.Listeners); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := grpcMock.start(currentProject, server.IP, server.GRPC); err != nil {
+		rawListeners.stop()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 先设置为运行状态，防止重复启动
 	server.IsRunning = true
 
 	server.engine = gin.New()
 	server.engine.Use(gin.Logger(), gin.Recovery())
+	// TLS下由net/http通过ALPN协商HTTP/2，明文时按配置支持h2c
+	server.engine.UseH2C = server.H2C && httpServer.TLSConfig == nil
 
 	// 设置动态路由处理
 	for _, endpoint := range server.Endpoints {
 		path := endpoint.Path
 		responseFile := endpoint.ResponseFile
 
+		switch endpoint.Type {
+		case endpointTypeWebSocket:
+			wsConfig := endpoint.WebSocket
+			server.engine.GET(path, func(c *gin.Context) {
+				handleWSMockEndpoint(c, path, wsConfig)
+			})
+			continue
+		case endpointTypeStream:
+			streamConfig := endpoint.Stream
+			server.engine.Any(path, func(c *gin.Context) {
+				handleStreamEndpoint(c, path, streamConfig)
+			})
+			continue
+		case endpointTypeGraphQL:
+			graphQLConfig := endpoint.GraphQL
+			server.engine.Any(path, func(c *gin.Context) {
+				handleGraphQLEndpoint(c, path, graphQLConfig)
+			})
+			continue
+		case endpointTypeSOAP:
+			soapConfig := endpoint.SOAP
+			server.engine.Any(path, func(c *gin.Context) {
+				handleSOAPEndpoint(c, path, soapConfig)
+			})
+			continue
+		case endpointTypeFile:
+			fileConfig := endpoint.File
+			server.engine.Any(path, func(c *gin.Context) {
+				handleFileEndpoint(c, path, responseFile, fileConfig)
+			})
+			continue
+		case endpointTypeFake:
+			fakeConfig := endpoint.Fake
+			server.engine.Any(path, func(c *gin.Context) {
+				handleFakeEndpoint(c, path, responseFile, fakeConfig)
+			})
+			continue
+		case endpointTypeResource:
+			registerResourceEndpoint(server.engine, endpoint)
+			continue
+		}
+
+		requestSchema := endpoint.RequestSchema
 		server.engine.Any(path, func(c *gin.Context) {
-			handleDynamicEndpoint(c, path, responseFile)
+			handleDynamicEndpoint(c, path, responseFile, requestSchema)
 		})
 	}
 
+	httpServer.Handler = server.engine.Handler()
+	server.httpServer = httpServer
+
 	// 在单独的goroutine中启动服务器
 	go func() {
-		addr := fmt.Sprintf("%s:%s", server.IP, server.Port)
-		log.Printf("HTTP服务器启动在 http://%s", addr)
-		err := server.engine.Run(addr)
+		log.Printf("HTTP服务器启动在 %s://%s", scheme, addr)
+		var err error
+		if httpServer.TLSConfig != nil {
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
 
-		if err != nil {
+		if err != nil && err != http.ErrServerClosed {
 			log.Printf("服务器启动失败: %v", err)
 			server.mu.Lock()
-			server.IsRunning = false
+			// 可能已被重新启动，只重置本次启动的状态
+			if server.httpServer == httpServer {
+				server.IsRunning = false
+				server.httpServer = nil
+				rawListeners.stop()
+				grpcMock.stop()
+			}
 			server.mu.Unlock()
 
 			// 通知前端启动失败
@@ -296,6 +500,14 @@ func stopServer(c *gin.Context) {
 		return
 	}
 
+	if server.httpServer != nil {
+		server.httpServer.Close()
+		server.httpServer = nil
+	}
+	// 已升级的WebSocket连接不受http.Server.Close影响，需要单独关闭
+	wsMocks.closeAll()
+	rawListeners.stop()
+	grpcMock.stop()
 	server.IsRunning = false
 	server.engine = nil
 
@@ -305,10 +517,15 @@ func stopServer(c *gin.Context) {
 
 func updateConfig(c *gin.Context) {
 	var config struct {
-		IP         string           `json:"ip"`
-		Port       string           `json:"port"`
-		Endpoints  []EndpointConfig `json:"endpoints"`
-		SendBlocks []SendBlock      `json:"send_blocks"`
+		IP            string              `json:"ip"`
+		Port          string              `json:"port"`
+		Endpoints     []EndpointConfig    `json:"endpoints"`
+		SendBlocks    []SendBlock         `json:"send_blocks"`
+		SendTransport *TransportConfig    `json:"send_transport"`
+		TLS           *ListenerTLSConfig  `json:"tls"`
+		H2C           bool                `json:"h2c"`
+		Listeners     []RawListenerConfig `json:"listeners"`
+		GRPC          *GRPCMockConfig     `json:"grpc"`
 	}
 
 	if err := c.ShouldBindJSON(&config); err != nil {
@@ -321,6 +538,11 @@ func updateConfig(c *gin.Context) {
 	server.Port = config.Port
 	server.Endpoints = config.Endpoints
 	server.SendBlocks = config.SendBlocks
+	server.SendTransport = config.SendTransport
+	server.TLS = config.TLS
+	server.H2C = config.H2C
+	server.Listeners = config.Listeners
+	server.GRPC = config.GRPC
 	server.mu.Unlock()
 
 	// 保存配置到文件
@@ -332,8 +554,51 @@ func updateConfig(c *gin.Context) {
 	broadcastToClients(map[string]interface{}{"type": "status_update", "data": server})
 }
 
-func handleDynamicEndpoint(c *gin.Context, path, responseFile string) {
-	// 记录请求
+func handleDynamicEndpoint(c *gin.Context, path, responseFile string, requestSchema *RequestSchemaConfig) {
+	requestLog := newRequestLog(c, path)
+	// GET和HEAD请求没有请求体，不做校验
+	if requestSchema != nil && requestSchema.File != "" && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		requestLog.SchemaValidation = validateRequestBody(currentProject, requestSchema, requestLog.Body)
+	}
+	appendRequestLog(requestLog)
+
+	if validation := requestLog.SchemaValidation; validation != nil && !validation.Valid && requestSchema.Reject {
+		writeSchemaRejection(c, requestSchema, validation)
+		return
+	}
+
+	// 返回响应数据
+	if responseFile != "" {
+		data, err := readProjectFile(currentProject, responseFile)
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{"message": "默认响应", "timestamp": time.Now()})
+		} else if isXMLFile(responseFile) {
+			c.Data(http.StatusOK, xmlContentType(data), data)
+		} else if !strings.EqualFold(filepath.Ext(responseFile), ".json") {
+			// 其他资源按扩展名返回对应的Content-Type
+			c.Data(http.StatusOK, assetMimeType(responseFile, data), data)
+		} else {
+			var jsonData interface{}
+			if json.Unmarshal(data, &jsonData) == nil {
+				c.JSON(http.StatusOK, jsonData)
+			} else {
+				c.String(http.StatusOK, string(data))
+			}
+		}
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "默认响应", "timestamp": time.Now()})
+	}
+}
+
+// 记录收到的请求
+func recordIncomingRequest(c *gin.Context, path string) RequestLog {
+	requestLog := newRequestLog(c, path)
+	appendRequestLog(requestLog)
+	return requestLog
+}
+
+// 根据收到的请求构造日志，读取请求体
+func newRequestLog(c *gin.Context, path string) RequestLog {
 	headers := make(map[string]interface{})
 	for k, v := range c.Request.Header {
 		headers[k] = v
@@ -341,15 +606,21 @@ func handleDynamicEndpoint(c *gin.Context, path, responseFile string) {
 
 	body, _ := io.ReadAll(c.Request.Body)
 
-	requestLog := RequestLog{
+	return RequestLog{
 		ID:        len(server.RequestLogs) + 1,
 		Path:      path,
 		Method:    c.Request.Method,
 		Headers:   headers,
 		Body:      string(body),
 		Timestamp: time.Now(),
+
+		Protocol:          negotiatedProtocol(c),
+		ClientCertSubject: peerCertSubject(c.Request),
 	}
+}
 
+// 保存并广播请求日志
+func appendRequestLog(requestLog RequestLog) {
 	server.mu.Lock()
 	server.RequestLogs = append(server.RequestLogs, requestLog)
 	// 只保留最新的100条记录
@@ -360,23 +631,6 @@ func handleDynamicEndpoint(c *gin.Context, path, responseFile string) {
 
 	// 广播新的请求日志
 	broadcastToClients(map[string]interface{}{"type": "new_request", "data": requestLog})
-
-	// 返回响应数据
-	if responseFile != "" {
-		data, err := os.ReadFile(filepath.Join(getJSONFilesPath(currentProject), responseFile))
-		if err != nil {
-			c.JSON(http.StatusOK, gin.H{"message": "默认响应", "timestamp": time.Now()})
-		} else {
-			var jsonData interface{}
-			if json.Unmarshal(data, &jsonData) == nil {
-				c.JSON(http.StatusOK, jsonData)
-			} else {
-				c.String(http.StatusOK, string(data))
-			}
-		}
-	} else {
-		c.JSON(http.StatusOK, gin.H{"message": "默认响应", "timestamp": time.Now()})
-	}
 }
 
 func getLogs(c *gin.Context) {
@@ -393,73 +647,92 @@ func sendRequest(c *gin.Context) {
 		return
 	}
 
-	// 创建HTTP请求
-	var reqBody io.Reader
-	if req.Data != "" {
-		reqBody = strings.NewReader(req.Data)
+	client, err := buildHTTPClient(currentProject, effectiveTransport(currentProject, req.Transport), 2)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	httpReq, err := http.NewRequest(req.Method, req.URL, reqBody)
+	result, err := executeSendRequest(client, currentProject, req, true)
+	sendHistory.record(currentProject, sendSourceManual, req, result, err)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 设置请求头
-	for k, v := range req.Headers {
-		httpReq.Header.Set(k, v)
-	}
+	c.JSON(http.StatusOK, result)
+}
 
-	// 如果没有设置Content-Type且有数据，默认设置为JSON
-	if req.Data != "" && httpReq.Header.Get("Content-Type") == "" {
-		httpReq.Header.Set("Content-Type", "application/json")
+// 执行一次发送请求，供发送接口和压测复用，文件类请求体从指定项目中读取
+// captureRaw为true时导出实际发送的原始请求，压测时关闭以免影响结果
+func executeSendRequest(client *http.Client, project string, req SendRequest, captureRaw bool) (*SendResult, error) {
+	// 签名写入请求体时需要先修改请求数据
+	sig, err := applyBodyAuth(&req)
+	if err != nil {
+		return nil, err
 	}
 
-	// 发送请求
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(httpReq)
+	// 创建HTTP请求
+	reqBody, contentType, err := buildRequestBody(project, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	httpReq, err := http.NewRequest(req.Method, req.URL, reqBody)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 
-	// 解析响应头
-	headers := make(map[string]string)
-	for k, v := range resp.Header {
-		if len(v) > 0 {
-			headers[k] = v[0]
-		}
+	// 设置请求头
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
 	}
 
-	response := map[string]interface{}{
-		"status":  resp.StatusCode,
-		"headers": headers,
-		"body":    string(respBody),
+	// multipart需要带boundary的Content-Type，始终覆盖
+	if req.BodyType == bodyTypeMultipart {
+		httpReq.Header.Set("Content-Type", contentType)
+	} else if contentType != "" && httpReq.Header.Get("Content-Type") == "" {
+		// 如果没有设置Content-Type且有数据，按请求体类型设置，默认为JSON
+		httpReq.Header.Set("Content-Type", contentType)
 	}
 
-	c.JSON(http.StatusOK, response)
-}
+	// 设置认证信息
+	if err := applyRequestAuth(client, httpReq, req.Auth, sig); err != nil {
+		return nil, err
+	}
 
-func listJSONFiles(c *gin.Context) {
-	files, err := filepath.Glob(filepath.Join(getJSONFilesPath(currentProject), "*.json"))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	// 记录实际发送的原始请求
+	rawRequest := ""
+	if captureRaw {
+		rawRequest = dumpRawRequest(httpReq)
 	}
 
-	var fileNames []string
-	for _, file := range files {
-		fileNames = append(fileNames, filepath.Base(file))
+	// 发送请求，同时记录各阶段耗时和重定向链
+	tracer := newSendTracer()
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), tracer.clientTrace()))
+	redirects := []RedirectHop{}
+	resp, err := withRedirectCapture(client, &redirects).Do(httpReq)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	c.JSON(http.StatusOK, fileNames)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendResult{
+		Status:     resp.StatusCode,
+		Proto:      resp.Proto,
+		Headers:    resp.Header,
+		Body:       string(respBody),
+		FinalURL:   resp.Request.URL.String(),
+		Redirects:  redirects,
+		TLS:        tlsInfoFromState(resp.TLS),
+		Timings:    tracer.finish(),
+		RawRequest: rawRequest,
+	}, nil
 }
 
 func saveJSONFile(c *gin.Context) {
@@ -473,22 +746,19 @@ func saveJSONFile(c *gin.Context) {
 		return
 	}
 
-	// 验证文件名安全性
-	if strings.Contains(request.Filename, "..") || strings.Contains(request.Filename, "/") || strings.Contains(request.Filename, "\\") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "非法文件名"})
+	// 验证文件名安全性，可以带子目录
+	if _, ok := projectFileParam(c, request.Filename); !ok {
 		return
 	}
 
-	// 验证JSON格式
-	var jsonData interface{}
-	if err := json.Unmarshal([]byte(request.Content), &jsonData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "JSON格式错误: " + err.Error()})
+	// 验证JSON或XML格式
+	if err := validateAssetContent(request.Filename, []byte(request.Content)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 保存文件
-	filePath := filepath.Join(getJSONFilesPath(currentProject), request.Filename)
-	if err := os.WriteFile(filePath, []byte(request.Content), 0644); err != nil {
+	// 保存文件，旧内容保留在版本历史中
+	if err := writeVersionedFile(currentProject, projectFileRevisionPath(request.Filename), []byte(request.Content), revisionActionSave); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败: " + err.Error()})
 		return
 	}
@@ -505,19 +775,24 @@ func readJSONFile(c *gin.Context) {
 	}
 
 	// 验证文件名安全性
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") || strings.Contains(filename, "\\") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "非法文件名"})
+	filePath, ok := projectFileParam(c, filename)
+	if !ok {
 		return
 	}
 
 	// 读取文件
-	filePath := filepath.Join(getJSONFilesPath(currentProject), filename)
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败: " + err.Error()})
 		return
 	}
 
+	// 非JSON文件按类型原样返回
+	if !strings.EqualFold(filepath.Ext(filename), ".json") {
+		c.Data(http.StatusOK, assetMimeType(filename, data), data)
+		return
+	}
+
 	// 解析JSON并返回
 	var jsonData interface{}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
@@ -533,10 +808,15 @@ const globalConfigFileName = "config.json"
 func saveConfig() error {
 	server.mu.RLock()
 	config := Config{
-		IP:         server.IP,
-		Port:       server.Port,
-		Endpoints:  server.Endpoints,
-		SendBlocks: server.SendBlocks,
+		IP:            server.IP,
+		Port:          server.Port,
+		Endpoints:     server.Endpoints,
+		SendBlocks:    server.SendBlocks,
+		SendTransport: server.SendTransport,
+		TLS:           server.TLS,
+		H2C:           server.H2C,
+		Listeners:     server.Listeners,
+		GRPC:          server.GRPC,
 	}
 	server.mu.RUnlock()
 
@@ -545,8 +825,7 @@ func saveConfig() error {
 		return err
 	}
 
-	configPath := filepath.Join(getProjectPath(currentProject), "config.json")
-	return os.WriteFile(configPath, data, 0644)
+	return writeVersionedFile(currentProject, "config.json", data, revisionActionSave)
 }
 
 func loadConfig() error {
@@ -570,6 +849,11 @@ func loadConfig() error {
 	server.Port = config.Port
 	server.Endpoints = config.Endpoints
 	server.SendBlocks = config.SendBlocks
+	server.SendTransport = config.SendTransport
+	server.TLS = config.TLS
+	server.H2C = config.H2C
+	server.Listeners = config.Listeners
+	server.GRPC = config.GRPC
 	server.mu.Unlock()
 
 	log.Printf("项目 %s 配置文件加载成功", currentProject)
@@ -611,6 +895,11 @@ func loadGlobalConfig() error {
 	if config.CurrentProject != "" {
 		currentProject = config.CurrentProject
 	}
+	// 记录的项目已被删除或归档时回到默认项目
+	if !projectExists(currentProject) || isProjectArchived(currentProject) {
+		log.Printf("项目 %s 不可用，切换到默认项目", currentProject)
+		currentProject = "default"
+	}
 
 	log.Printf("全局配置加载成功，当前项目: %s", currentProject)
 	return nil
@@ -675,23 +964,37 @@ func listProjects(c *gin.Context) {
 
 	var projects []ProjectInfo
 	for _, entry := range entries {
-		if entry.IsDir() {
+		// 隐藏目录是导入时的临时目录
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 			info, _ := entry.Info()
-			projects = append(projects, ProjectInfo{
+			project := ProjectInfo{
 				Name:      entry.Name(),
 				Path:      getProjectPath(entry.Name()),
 				CreatedAt: info.ModTime().Format("2006-01-02 15:04:05"),
-			})
+			}
+			if data, err := os.ReadFile(filepath.Join(project.Path, archivedMarkerFile)); err == nil {
+				project.Archived = true
+				project.ArchivedAt = string(data)
+			}
+			projects = append(projects, project)
 		}
 	}
 
 	c.JSON(http.StatusOK, projects)
 }
 
-// API: 创建新项目
+// API: 创建新项目，可以指定内置模板或已有项目作为模板，也可以上传归档
 func createProject(c *gin.Context) {
+	// 上传归档作为模板
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		createProjectFromArchive(c)
+		return
+	}
+
 	var request struct {
-		Name string `json:"name"`
+		Name      string              `json:"name"`
+		Template  *ProjectTemplateRef `json:"template"`
+		Variables map[string]string   `json:"variables"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -700,7 +1003,7 @@ func createProject(c *gin.Context) {
 	}
 
 	// 验证项目名
-	if request.Name == "" || strings.Contains(request.Name, "..") || strings.Contains(request.Name, "/") || strings.Contains(request.Name, "\\") {
+	if !isValidProjectName(request.Name) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "非法项目名"})
 		return
 	}
@@ -711,6 +1014,11 @@ func createProject(c *gin.Context) {
 		return
 	}
 
+	if request.Template != nil {
+		createProjectFromTemplate(c, request.Name, *request.Template, request.Variables)
+		return
+	}
+
 	// 创建项目目录
 	if err := os.MkdirAll(projectPath, 0755); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建项目失败: " + err.Error()})
@@ -724,7 +1032,15 @@ func createProject(c *gin.Context) {
 	}
 
 	// 创建默认配置文件
-	defaultConfig := Config{
+	data, _ := json.MarshalIndent(defaultProjectConfig(), "", "  ")
+	os.WriteFile(getConfigPath(request.Name), data, 0644)
+
+	c.JSON(http.StatusOK, gin.H{"message": "项目创建成功"})
+}
+
+// 新项目的默认配置
+func defaultProjectConfig() Config {
+	return Config{
 		IP:   "0.0.0.0",
 		Port: "29800",
 		Endpoints: []EndpointConfig{
@@ -738,11 +1054,6 @@ func createProject(c *gin.Context) {
 			{Name: "", URL: "", SendFile: "", Method: "POST", Headers: `{"content-type":"application/json"}`},
 		},
 	}
-
-	data, _ := json.MarshalIndent(defaultConfig, "", "  ")
-	os.WriteFile(getConfigPath(request.Name), data, 0644)
-
-	c.JSON(http.StatusOK, gin.H{"message": "项目创建成功"})
 }
 
 // API: 切换项目
@@ -762,6 +1073,10 @@ func switchProject(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "项目不存在"})
 		return
 	}
+	if isProjectArchived(request.Project) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目已归档，请先取消归档"})
+		return
+	}
 
 	// 保存当前项目配置
 	if err := saveConfig(); err != nil {
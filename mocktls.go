@@ -0,0 +1,290 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 客户端证书校验方式
+const (
+	clientAuthNone    = ""
+	clientAuthRequest = "request"
+	clientAuthRequire = "require"
+	clientAuthVerify  = "verify"
+)
+
+// 模拟服务器的TLS配置，未指定证书时使用自动生成的本地CA签发
+type ListenerTLSConfig struct {
+	Enabled      bool     `json:"enabled"`
+	CertFile     string   `json:"cert_file,omitempty"`
+	KeyFile      string   `json:"key_file,omitempty"`
+	Hostnames    []string `json:"hostnames,omitempty"`
+	ClientAuth   string   `json:"client_auth,omitempty"`
+	ClientCAFile string   `json:"client_ca_file,omitempty"`
+	DisableHTTP2 bool     `json:"disable_http2,omitempty"`
+}
+
+// 本地CA全局共享，便于客户端只信任一次
+const certsDir = "certs"
+
+func getCAPaths() (string, string) {
+	return filepath.Join(certsDir, "ca.pem"), filepath.Join(certsDir, "ca-key.pem")
+}
+
+func getProjectCertPaths(project string) (string, string) {
+	dir := filepath.Join(getProjectPath(project), "certs")
+	return filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem")
+}
+
+func writePEM(path, blockType string, der []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// 读取本地CA，不存在时生成
+func loadOrCreateCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath, keyPath := getCAPaths()
+	if pair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err == nil && time.Now().Before(cert.NotAfter) {
+			if key, ok := pair.PrivateKey.(*ecdsa.PrivateKey); ok {
+				return cert, key, nil
+			}
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "HTTP+JSON Tool Local CA", Organization: []string{"http-json-tool"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writePEM(certPath, "CERTIFICATE", der); err != nil {
+		return nil, nil, err
+	}
+	if err := writePEM(keyPath, "EC PRIVATE KEY", keyDER); err != nil {
+		return nil, nil, err
+	}
+	log.Printf("已生成本地CA证书: %s", certPath)
+
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+// 证书是否覆盖所有需要的主机名和IP
+func certCovers(cert *x509.Certificate, hosts []string) bool {
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// 监听地址、localhost以及额外配置的主机名
+func listenerHostnames(ip string, extra []string) []string {
+	hosts := []string{"localhost", "127.0.0.1"}
+	if ip != "" && ip != "0.0.0.0" && ip != "::" {
+		hosts = append(hosts, ip)
+	}
+	return append(hosts, extra...)
+}
+
+// 读取项目的服务端证书，不存在、过期或主机名不匹配时用本地CA重新签发
+func loadOrCreateLeafCert(project string, hosts []string) (tls.Certificate, error) {
+	certPath, keyPath := getProjectCertPaths(project)
+	if pair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		if leaf, err := x509.ParseCertificate(pair.Certificate[0]); err == nil &&
+			time.Now().Add(24*time.Hour).Before(leaf.NotAfter) && certCovers(leaf, hosts) {
+			return pair, nil
+		}
+	}
+
+	caCert, caKey, err := loadOrCreateCA()
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("加载本地CA失败: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hosts[0], Organization: []string{"http-json-tool"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	// 证书文件中附带CA证书，组成完整证书链
+	chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})...)
+	if err := os.MkdirAll(filepath.Dir(certPath), 0755); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(certPath, chain, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := writePEM(keyPath, "EC PRIVATE KEY", keyDER); err != nil {
+		return tls.Certificate{}, err
+	}
+	log.Printf("已为项目 %s 签发服务端证书: %v", project, hosts)
+
+	return tls.LoadX509KeyPair(certPath, keyPath)
+}
+
+// 根据配置创建模拟服务器的TLS配置
+func buildListenerTLSConfig(project, ip string, cfg *ListenerTLSConfig) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		certPath, err := resolveProjectFile(project, cfg.CertFile)
+		if err != nil {
+			return nil, err
+		}
+		keyPath, err := resolveProjectFile(project, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if cert, err = tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+			return nil, fmt.Errorf("加载服务端证书失败: %v", err)
+		}
+	} else if cert, err = loadOrCreateLeafCert(project, listenerHostnames(ip, cfg.Hostnames)); err != nil {
+		return nil, fmt.Errorf("生成服务端证书失败: %v", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	switch cfg.ClientAuth {
+	case clientAuthNone:
+		return tlsConfig, nil
+	case clientAuthRequest:
+		tlsConfig.ClientAuth = tls.RequestClientCert
+		return tlsConfig, nil
+	case clientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+		return tlsConfig, nil
+	case clientAuthVerify:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("不支持的客户端证书校验方式: %s", cfg.ClientAuth)
+	}
+
+	// 校验客户端证书时默认信任本地CA
+	caPath, _ := getCAPaths()
+	if cfg.ClientCAFile != "" {
+		if caPath, err = resolveProjectFile(project, cfg.ClientCAFile); err != nil {
+			return nil, err
+		}
+	} else if _, _, err := loadOrCreateCA(); err != nil {
+		return nil, err
+	}
+	caPEM, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取客户端CA证书失败: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("客户端CA证书格式错误")
+	}
+	tlsConfig.ClientCAs = pool
+	return tlsConfig, nil
+}
+
+// 请求携带的客户端证书主题
+func peerCertSubject(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return ""
+	}
+	return r.TLS.PeerCertificates[0].Subject.String()
+}
+
+// 请求实际使用的协议，区分TLS上的h2和明文的h2c
+func negotiatedProtocol(c *gin.Context) string {
+	r := c.Request
+	// 通过Upgrade升级到h2c的首个请求仍保留HTTP/1.1的协议号，但响应已经由HTTP/2连接写出，
+	// 只有HTTP/2的ResponseWriter支持Pusher
+	if r.ProtoMajor == 2 || c.Writer.Pusher() != nil {
+		if r.TLS != nil {
+			return "h2"
+		}
+		return "h2c"
+	}
+	return r.Proto
+}
+
+// API: 下载本地CA证书，供客户端导入信任
+func downloadCACert(c *gin.Context) {
+	if _, _, err := loadOrCreateCA(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成CA证书失败: " + err.Error()})
+		return
+	}
+	certPath, _ := getCAPaths()
+	c.FileAttachment(certPath, "http-json-tool-ca.crt")
+}
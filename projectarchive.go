@@ -0,0 +1,545 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 工具版本，写入导出清单，发布时可通过 -ldflags "-X main.toolVersion=x.y.z" 覆盖
+var toolVersion = "1.0.0"
+
+// 导出格式版本，结构变化时递增并在migrateImportedProject中兼容旧版本。
+// 0表示没有清单的手工打包目录
+const projectArchiveFormatVersion = 1
+
+const projectManifestFile = "manifest.json"
+
+// 导入限制
+const (
+	maxImportArchiveSize  = 100 << 20
+	maxImportUnpackedSize = 500 << 20
+	maxImportFiles        = 5000
+)
+
+// 同名项目的处理方式
+const (
+	importConflictRename    = "rename"
+	importConflictOverwrite = "overwrite"
+	importConflictFail      = "fail"
+)
+
+// 导出归档中的清单
+type ProjectManifest struct {
+	FormatVersion int       `json:"format_version"`
+	ToolVersion   string    `json:"tool_version"`
+	Project       string    `json:"project"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Files         []string  `json:"files"`
+}
+
+// 列出要导出的项目文件，使用/分隔的相对路径
+func projectExportFiles(project string) ([]string, error) {
+	root := getProjectPath(project)
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if projectCloneSkip[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, err
+}
+
+// API: 导出项目为zip（默认）或tar.gz归档，包含清单、配置和全部项目资源
+func exportProject(c *gin.Context) {
+	name, ok := projectParam(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "zip")
+	if format != "zip" && format != "tar.gz" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "仅支持zip和tar.gz格式"})
+		return
+	}
+
+	// 当前项目先保存，导出最新的配置
+	if name == currentProject {
+		if err := saveConfig(); err != nil {
+			log.Printf("保存当前项目配置失败: %v", err)
+		}
+	}
+
+	files, err := projectExportFiles(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取项目文件失败: " + err.Error()})
+		return
+	}
+	manifest, _ := json.MarshalIndent(ProjectManifest{
+		FormatVersion: projectArchiveFormatVersion,
+		ToolVersion:   toolVersion,
+		Project:       name,
+		ExportedAt:    time.Now(),
+		Files:         files,
+	}, "", "  ")
+
+	contentType := "application/zip"
+	if format == "tar.gz" {
+		contentType = "application/gzip"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + "." + format}))
+	c.Status(http.StatusOK)
+
+	root := getProjectPath(name)
+	if format == "zip" {
+		err = writeProjectZip(c.Writer, root, files, manifest)
+	} else {
+		err = writeProjectTarGz(c.Writer, root, files, manifest)
+	}
+	// 响应头已发送，只能记录错误
+	if err != nil {
+		log.Printf("导出项目 %s 失败: %v", name, err)
+	}
+}
+
+func writeProjectZip(w io.Writer, root string, files []string, manifest []byte) error {
+	zw := zip.NewWriter(w)
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: projectManifestFile, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	if _, err := mw.Write(manifest); err != nil {
+		return err
+	}
+	for _, name := range files {
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := copyFileTo(fw, filepath.Join(root, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeProjectTarGz(w io.Writer, root string, files []string, manifest []byte) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: projectManifestFile, Mode: 0644, Size: int64(len(manifest)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+	for _, name := range files {
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+			return err
+		}
+		if err := copyFileTo(tw, filepath.Join(root, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func copyFileTo(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// 解压时的安全检查和大小统计
+type archiveExtractor struct {
+	dir      string
+	files    int
+	unpacked int64
+}
+
+// 检查归档中的路径，拒绝绝对路径、盘符和..
+func sanitizeArchivePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return "", fmt.Errorf("归档包含绝对路径: %s", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("归档包含非法路径: %s", name)
+		}
+	}
+	return path.Clean(name), nil
+}
+
+func (e *archiveExtractor) extract(name string, isDir bool, r io.Reader) error {
+	clean, err := sanitizeArchivePath(name)
+	if err != nil {
+		return err
+	}
+	if clean == "." {
+		return nil
+	}
+	target := filepath.Join(e.dir, filepath.FromSlash(clean))
+	if isDir {
+		return os.MkdirAll(target, 0755)
+	}
+
+	e.files++
+	if e.files > maxImportFiles {
+		return fmt.Errorf("归档文件数超过%d个", maxImportFiles)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	// 多读一个字节用于判断是否超出总大小限制
+	remaining := int64(maxImportUnpackedSize) - e.unpacked
+	n, err := io.Copy(out, io.LimitReader(r, remaining+1))
+	out.Close()
+	e.unpacked += n
+	if err != nil {
+		return err
+	}
+	if e.unpacked > maxImportUnpackedSize {
+		return fmt.Errorf("解压后大小超过%dMB", maxImportUnpackedSize>>20)
+	}
+	return nil
+}
+
+func (e *archiveExtractor) extractZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("zip格式错误: %v", err)
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if mode&fs.ModeSymlink != 0 || (!mode.IsDir() && !mode.IsRegular()) {
+			return fmt.Errorf("归档包含不支持的文件类型: %s", f.Name)
+		}
+		if mode.IsDir() {
+			if err := e.extract(f.Name, true, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = e.extract(f.Name, false, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *archiveExtractor) extractTarGz(r io.Reader) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("gzip格式错误: %v", err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar格式错误: %v", err)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = e.extract(header.Name, true, nil)
+		case tar.TypeReg:
+			err = e.extract(header.Name, false, tr)
+		case tar.TypeXGlobalHeader:
+		default:
+			return fmt.Errorf("归档包含不支持的文件类型: %s", header.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// 找到项目根目录：手工打包时常带一层项目目录
+func importedProjectRoot(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "config.json")); err == nil {
+		return dir
+	}
+	if _, err := os.Stat(filepath.Join(dir, projectManifestFile)); err == nil {
+		return dir
+	}
+	entries, err := os.ReadDir(dir)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() && entries[0].Name() != "json_files" {
+		return filepath.Join(dir, entries[0].Name())
+	}
+	return dir
+}
+
+// 按清单的格式版本迁移为当前的项目结构
+func migrateImportedProject(root string, version int) error {
+	if version > projectArchiveFormatVersion {
+		return fmt.Errorf("归档格式版本%d高于当前工具支持的版本%d，请升级工具", version, projectArchiveFormatVersion)
+	}
+
+	if version < 1 {
+		// 早期版本的响应文件直接放在根目录
+		if err := os.MkdirAll(filepath.Join(root, "json_files"), 0755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || name == "config.json" || name == "jobs.json" || name == "job_history.json" {
+				continue
+			}
+			if ext := strings.ToLower(filepath.Ext(name)); ext == ".json" || ext == ".xml" {
+				if err := os.Rename(filepath.Join(root, name), filepath.Join(root, "json_files", name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	configPath := filepath.Join(root, "config.json")
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		data, _ = json.MarshalIndent(defaultProjectConfig(), "", "  ")
+		return os.WriteFile(configPath, data, 0644)
+	}
+	if err != nil {
+		return err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("config.json格式错误: %v", err)
+	}
+	return os.MkdirAll(filepath.Join(root, "json_files"), 0755)
+}
+
+// 为导入的项目确定名称，rename时在已存在的名称后追加序号
+func resolveImportName(name, onConflict string) (string, error) {
+	if !projectExists(name) {
+		return name, nil
+	}
+	switch onConflict {
+	case importConflictOverwrite:
+		if name == currentProject {
+			return "", errors.New("不能覆盖当前项目")
+		}
+		return name, nil
+	case importConflictFail:
+		return "", fmt.Errorf("项目 %s 已存在", name)
+	}
+	for i := 2; ; i++ {
+		candidate := name + "-" + strconv.Itoa(i)
+		if !projectExists(candidate) {
+			return candidate, nil
+		}
+	}
+}
+
+// API: 导入项目归档（multipart字段file，可选name和on_conflict）
+func importProject(c *gin.Context) {
+	importUploadedProject(c, importConflictRename, nil, "")
+}
+
+// 解压上传的归档并保存为项目，values不为空时按模板替换变量
+func importUploadedProject(c *gin.Context, defaultConflict string, values map[string]string, port string) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportArchiveSize+1<<20)
+	upload, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有上传文件或文件过大: " + err.Error()})
+		return
+	}
+	if upload.Size > maxImportArchiveSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("归档大小超过%dMB", maxImportArchiveSize>>20)})
+		return
+	}
+	onConflict := c.DefaultPostForm("on_conflict", defaultConflict)
+	if onConflict != importConflictRename && onConflict != importConflictOverwrite && onConflict != importConflictFail {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "on_conflict只能是rename、overwrite或fail"})
+		return
+	}
+
+	file, err := upload.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	tmp, err := os.MkdirTemp("projects", ".import-")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	// 按文件头判断格式
+	extractor := &archiveExtractor{dir: tmp}
+	magic, _ := bufio.NewReader(file).Peek(4)
+	// zip需要随机读取，不能用MultiReader拼回已读的文件头
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取上传文件失败: " + err.Error()})
+		return
+	}
+	switch {
+	case len(magic) >= 4 && string(magic[:4]) == "PK\x03\x04":
+		err = extractor.extractZip(file, upload.Size)
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		err = extractor.extractTarGz(file)
+	default:
+		err = errors.New("仅支持zip和tar.gz归档")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "归档无效: " + err.Error()})
+		return
+	}
+
+	root := importedProjectRoot(tmp)
+	var manifest ProjectManifest
+	if data, err := os.ReadFile(filepath.Join(root, projectManifestFile)); err == nil {
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "清单格式错误: " + err.Error()})
+			return
+		}
+		os.Remove(filepath.Join(root, projectManifestFile))
+	}
+	// 导入的项目不带归档标记
+	os.Remove(filepath.Join(root, archivedMarkerFile))
+	if err := migrateImportedProject(root, manifest.FormatVersion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if values != nil {
+		if err := applyTemplateVariables(root, values, port); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = manifest.Project
+	}
+	if name == "" && root != tmp {
+		name = filepath.Base(root)
+	}
+	if name == "" {
+		name = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(upload.Filename, ".zip"), ".gz"), ".tar")
+	}
+	if !isValidProjectName(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法项目名: " + name})
+		return
+	}
+	name, err = resolveImportName(name, onConflict)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 覆盖时先把原项目移到一边，新项目就位后再删除，失败时恢复原项目
+	backup := ""
+	if projectExists(name) {
+		scheduler.unloadProject(name)
+		sendHistory.forget(name)
+		backup = filepath.Join("projects", fmt.Sprintf(".replaced-%s-%d", name, time.Now().UnixNano()))
+		if err := os.Rename(getProjectPath(name), backup); err != nil {
+			if loadErr := scheduler.reloadProject(name); loadErr != nil {
+				log.Printf("重新加载项目 %s 的任务失败: %v", name, loadErr)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "覆盖项目失败: " + err.Error()})
+			return
+		}
+	}
+	if err := os.Rename(root, getProjectPath(name)); err != nil {
+		if backup != "" {
+			if restoreErr := os.Rename(backup, getProjectPath(name)); restoreErr != nil {
+				log.Printf("恢复项目 %s 失败，原项目保留在 %s: %v", name, backup, restoreErr)
+			} else if loadErr := scheduler.reloadProject(name); loadErr != nil {
+				log.Printf("重新加载项目 %s 的任务失败: %v", name, loadErr)
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存项目失败: " + err.Error()})
+		return
+	}
+	if backup != "" {
+		if err := os.RemoveAll(backup); err != nil {
+			log.Printf("删除被覆盖的项目 %s 失败: %v", backup, err)
+		}
+	}
+	if err := scheduler.loadPaused(name); err != nil {
+		log.Printf("加载项目 %s 的定时任务失败: %v", name, err)
+	}
+
+	log.Printf("项目 %s 导入成功（格式版本%d，工具版本%s）", name, manifest.FormatVersion, manifest.ToolVersion)
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "项目导入成功",
+		"name":           name,
+		"format_version": manifest.FormatVersion,
+		"tool_version":   manifest.ToolVersion,
+		"files":          extractor.files,
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSanitizeArchivePath(t *testing.T) {
+	valid := map[string]string{
+		"config.json":          "config.json",
+		"json_files/a.json":    "json_files/a.json",
+		"./json_files/./a.xml": "json_files/a.xml",
+		"json_files//a.json":   "json_files/a.json",
+		"json_files/":          "json_files",
+		"json_files\\a.json":   "json_files/a.json",
+		"a..b/c..json":         "a..b/c..json",
+	}
+	for name, want := range valid {
+		got, err := sanitizeArchivePath(name)
+		if err != nil || got != want {
+			t.Errorf("sanitizeArchivePath(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	for _, name := range []string{
+		"../x",
+		"..",
+		"a/../../x",
+		"a/../x",
+		"json_files/..",
+		"/etc/passwd",
+		"//server/share/x",
+		"C:\\x",
+		"C:/x",
+		"c:x",
+		"..\\x",
+		"a\\..\\..\\x",
+		"\\x",
+		"\\\\server\\share\\x",
+	} {
+		if got, err := sanitizeArchivePath(name); err == nil {
+			t.Errorf("sanitizeArchivePath(%q) = %q, 应返回错误", name, got)
+		}
+	}
+}
+
+func zipArchive(t *testing.T, headers ...*zip.FileHeader) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, header := range headers {
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("target"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func tarGzArchive(t *testing.T, headers ...*tar.Header) *bytes.Buffer {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, header := range headers {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			tw.Write(make([]byte, header.Size))
+		}
+	}
+	tw.Close()
+	gw.Close()
+	return &buf
+}
+
+// 解压被拒绝后目标目录外不能出现文件
+func assertNothingOutside(t *testing.T, parent string) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "dest" {
+			t.Errorf("解压写到了目标目录之外: %s", entry.Name())
+		}
+	}
+}
+
+func TestExtractZipRejectsUnsafeEntries(t *testing.T) {
+	symlink := &zip.FileHeader{Name: "json_files/link"}
+	symlink.SetMode(fs.ModeSymlink | 0777)
+
+	tests := map[string]*zip.FileHeader{
+		"上级目录":   {Name: "../evil.json"},
+		"嵌套上级目录": {Name: "a/../../evil.json"},
+		"绝对路径":   {Name: "/tmp/evil.json"},
+		"盘符":     {Name: "C:\\evil.json"},
+		"反斜杠":    {Name: "..\\evil.json"},
+		"符号链接":   symlink,
+	}
+	for name, header := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent := t.TempDir()
+			archive := zipArchive(t, header)
+			extractor := &archiveExtractor{dir: filepath.Join(parent, "dest")}
+			if err := extractor.extractZip(archive, archive.Size()); err == nil {
+				t.Fatalf("%s 应被拒绝", header.Name)
+			}
+			assertNothingOutside(t, parent)
+		})
+	}
+}
+
+func TestExtractTarGzRejectsUnsafeEntries(t *testing.T) {
+	tests := map[string]*tar.Header{
+		"上级目录":   {Name: "../evil.json", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+		"嵌套上级目录": {Name: "a/../../evil.json", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+		"绝对路径":   {Name: "/tmp/evil.json", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+		"符号链接":   {Name: "json_files/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd", Mode: 0777},
+		"硬链接":    {Name: "json_files/link", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd", Mode: 0644},
+	}
+	for name, header := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent := t.TempDir()
+			extractor := &archiveExtractor{dir: filepath.Join(parent, "dest")}
+			if err := extractor.extractTarGz(tarGzArchive(t, header)); err == nil {
+				t.Fatalf("%s 应被拒绝", header.Name)
+			}
+			assertNothingOutside(t, parent)
+		})
+	}
+}
+
+func TestExtractZipAcceptsProjectFiles(t *testing.T) {
+	dir := t.TempDir()
+	archive := zipArchive(t, &zip.FileHeader{Name: "config.json"}, &zip.FileHeader{Name: "json_files/orders/a.json"})
+	extractor := &archiveExtractor{dir: dir}
+	if err := extractor.extractZip(archive, archive.Size()); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "json_files", "orders", "a.json")); err != nil || string(data) != "target" {
+		t.Errorf("解压结果错误: %q, %v", data, err)
+	}
+}
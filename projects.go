@@ -0,0 +1,320 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 归档标记文件，存在时项目不能切换，定时任务不运行
+const archivedMarkerFile = ".archived"
+
+// 删除确认令牌的有效期
+const projectDeleteTokenTTL = 2 * time.Minute
+
+// 克隆时不复制的运行记录、版本历史和证书，新项目重新生成
+var projectCloneSkip = map[string]bool{
+	archivedMarkerFile:   true,
+	projectHistoryDir:    true,
+	resourceStateDir:     true,
+	"send_history.jsonl": true,
+	"job_history.json":   true,
+	"certs":              true,
+}
+
+type projectDeleteToken struct {
+	token   string
+	expires time.Time
+}
+
+var projectDeleteTokens = struct {
+	sync.Mutex
+	tokens map[string]projectDeleteToken
+}{tokens: make(map[string]projectDeleteToken)}
+
+func isValidProjectName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.Contains(name, "..") && !strings.ContainsAny(name, "/\\")
+}
+
+func projectExists(name string) bool {
+	info, err := os.Stat(getProjectPath(name))
+	return err == nil && info.IsDir()
+}
+
+func isProjectArchived(project string) bool {
+	_, err := os.Stat(filepath.Join(getProjectPath(project), archivedMarkerFile))
+	return err == nil
+}
+
+// 校验路径参数中的项目名，失败时已写入响应
+func projectParam(c *gin.Context) (string, bool) {
+	name := c.Param("name")
+	if !isValidProjectName(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法项目名"})
+		return "", false
+	}
+	if !projectExists(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "项目不存在"})
+		return "", false
+	}
+	return name, true
+}
+
+// 读取请求中的新项目名并检查是否可用，失败时已写入响应
+func newProjectNameParam(c *gin.Context) (string, bool) {
+	var request struct {
+		NewName string `json:"new_name"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if !isValidProjectName(request.NewName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法项目名"})
+		return "", false
+	}
+	if _, err := os.Stat(getProjectPath(request.NewName)); err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目已存在"})
+		return "", false
+	}
+	return request.NewName, true
+}
+
+func countProjectFiles(project string) int {
+	count := 0
+	filepath.WalkDir(getProjectPath(project), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// 复制项目目录，skip中的顶层文件和目录不复制
+func copyProjectDir(src, dst string, skip map[string]bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if skip[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+func newDeleteToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// API: 删除项目。第一次请求返回409和确认令牌，带上confirm_token再次请求才会删除
+func deleteProject(c *gin.Context) {
+	name, ok := projectParam(c)
+	if !ok {
+		return
+	}
+	if name == currentProject {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能删除当前项目，请先切换到其他项目"})
+		return
+	}
+
+	token := c.Query("confirm_token")
+	projectDeleteTokens.Lock()
+	issued, found := projectDeleteTokens.tokens[name]
+	valid := found && token != "" && token == issued.token && time.Now().Before(issued.expires)
+	if !valid {
+		issued = projectDeleteToken{token: newDeleteToken(), expires: time.Now().Add(projectDeleteTokenTTL)}
+		projectDeleteTokens.tokens[name] = issued
+	} else {
+		delete(projectDeleteTokens.tokens, name)
+	}
+	projectDeleteTokens.Unlock()
+
+	if !valid {
+		message := "删除项目需要确认"
+		if token != "" {
+			message = "确认令牌无效或已过期，请重新确认"
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         message,
+			"confirm_token": issued.token,
+			"expires_in":    int(projectDeleteTokenTTL.Seconds()),
+			"files":         countProjectFiles(name),
+		})
+		return
+	}
+
+	scheduler.unloadProject(name)
+	sendHistory.forget(name)
+	if err := os.RemoveAll(getProjectPath(name)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除项目失败: " + err.Error()})
+		return
+	}
+
+	log.Printf("项目 %s 已删除", name)
+	c.JSON(http.StatusOK, gin.H{"message": "项目删除成功"})
+}
+
+// API: 重命名项目，当前项目重命名后同步更新全局配置
+func renameProject(c *gin.Context) {
+	name, ok := projectParam(c)
+	if !ok {
+		return
+	}
+	newName, ok := newProjectNameParam(c)
+	if !ok {
+		return
+	}
+
+	active := name == currentProject
+	server.mu.RLock()
+	running := server.IsRunning
+	server.mu.RUnlock()
+	// 监听和gRPC服务启动时绑定了项目目录
+	if active && running {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请先停止服务器再重命名当前项目"})
+		return
+	}
+
+	scheduler.unloadProject(name)
+	sendHistory.forget(name)
+	if err := os.Rename(getProjectPath(name), getProjectPath(newName)); err != nil {
+		scheduler.reloadProject(name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "重命名项目失败: " + err.Error()})
+		return
+	}
+	if !isProjectArchived(newName) {
+		if err := scheduler.reloadProject(newName); err != nil {
+			log.Printf("加载项目 %s 的定时任务失败: %v", newName, err)
+		}
+	}
+
+	if active {
+		currentProject = newName
+		if err := saveGlobalConfig(); err != nil {
+			log.Printf("保存全局配置失败: %v", err)
+		}
+		broadcastToClients(map[string]interface{}{"type": "status_update", "data": server})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "项目重命名成功", "name": newName})
+}
+
+// API: 克隆项目，复制配置、响应文件和其他项目资源，定时任务克隆后为暂停状态
+func cloneProject(c *gin.Context) {
+	name, ok := projectParam(c)
+	if !ok {
+		return
+	}
+	newName, ok := newProjectNameParam(c)
+	if !ok {
+		return
+	}
+
+	// 当前项目先保存，克隆最新的配置
+	if name == currentProject {
+		if err := saveConfig(); err != nil {
+			log.Printf("保存当前项目配置失败: %v", err)
+		}
+	}
+
+	if err := copyProjectDir(getProjectPath(name), getProjectPath(newName), projectCloneSkip); err != nil {
+		os.RemoveAll(getProjectPath(newName))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "克隆项目失败: " + err.Error()})
+		return
+	}
+
+	if err := scheduler.loadPaused(newName); err != nil {
+		log.Printf("加载项目 %s 的定时任务失败: %v", newName, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "项目克隆成功", "name": newName})
+}
+
+// API: 归档项目，归档后停止其定时任务
+func archiveProject(c *gin.Context) {
+	name, ok := projectParam(c)
+	if !ok {
+		return
+	}
+	if name == currentProject {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能归档当前项目，请先切换到其他项目"})
+		return
+	}
+	if isProjectArchived(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目已归档"})
+		return
+	}
+
+	marker := filepath.Join(getProjectPath(name), archivedMarkerFile)
+	if err := os.WriteFile(marker, []byte(time.Now().Format(time.RFC3339)), 0644); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "归档项目失败: " + err.Error()})
+		return
+	}
+	scheduler.unloadProject(name)
+
+	c.JSON(http.StatusOK, gin.H{"message": "项目归档成功"})
+}
+
+// API: 取消归档，恢复之前运行中的定时任务
+func unarchiveProject(c *gin.Context) {
+	name, ok := projectParam(c)
+	if !ok {
+		return
+	}
+	if !isProjectArchived(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目未归档"})
+		return
+	}
+
+	if err := os.Remove(filepath.Join(getProjectPath(name), archivedMarkerFile)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消归档失败: " + err.Error()})
+		return
+	}
+	if err := scheduler.reloadProject(name); err != nil {
+		log.Printf("加载项目 %s 的定时任务失败: %v", name, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "项目已取消归档"})
+}
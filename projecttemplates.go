@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 模板来源：内置模板、已有项目、上传的归档
+const (
+	templateSourceBuiltin = "builtin"
+	templateSourceProject = "project"
+)
+
+// 模板中的变量占位符，如{{base_path}}，未提供的变量原样保留
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+var templateVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 创建项目时引用的模板
+type ProjectTemplateRef struct {
+	Source string `json:"source"`
+	Name   string `json:"name"`
+}
+
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// 默认值可以引用前面的变量，如http://127.0.0.1:{{port}}
+	Default string `json:"default,omitempty"`
+}
+
+// 内置项目模板，配置和文件中的字符串可以使用变量占位符
+type BuiltinProjectTemplate struct {
+	Name        string             `json:"name"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Variables   []TemplateVariable `json:"variables"`
+	config      Config
+	files       map[string]string
+}
+
+// 所有模板都可以使用的变量
+var commonTemplateVariables = []TemplateVariable{
+	{Name: "project", Description: "新项目名称，自动填写"},
+	{Name: "base_path", Description: "接口路径前缀", Default: "/api"},
+	{Name: "port", Description: "监听端口", Default: "29800"},
+}
+
+var builtinProjectTemplates = []BuiltinProjectTemplate{
+	{
+		Name:        "rest-crud",
+		Title:       "REST CRUD",
+		Description: "用户资源的列表、详情、创建、更新、删除接口和对应的测试请求",
+		Variables: []TemplateVariable{
+			{Name: "resource", Description: "资源名称，用于接口路径", Default: "users"},
+		},
+		config: Config{
+			Endpoints: []EndpointConfig{
+				{Name: "列表和创建", Path: "{{base_path}}/{{resource}}", ResponseFile: "list.json"},
+				{Name: "详情、更新和删除", Path: "{{base_path}}/{{resource}}/:id", ResponseFile: "item.json"},
+			},
+			SendBlocks: []SendBlock{
+				{Name: "查询列表", URL: "http://127.0.0.1:{{port}}{{base_path}}/{{resource}}", Method: "GET", Headers: "{}"},
+				{Name: "创建", URL: "http://127.0.0.1:{{port}}{{base_path}}/{{resource}}", SendFile: "create.json", Method: "POST", Headers: `{"Content-Type": "application/json"}`},
+				{Name: "更新", URL: "http://127.0.0.1:{{port}}{{base_path}}/{{resource}}/1", SendFile: "create.json", Method: "PUT", Headers: `{"Content-Type": "application/json"}`},
+				{Name: "删除", URL: "http://127.0.0.1:{{port}}{{base_path}}/{{resource}}/1", Method: "DELETE", Headers: "{}"},
+			},
+		},
+		files: map[string]string{
+			"list.json": `{
+    "code": 0,
+    "message": "success",
+    "data": {
+        "items": [
+            {"id": 1, "name": "张三", "email": "zhangsan@example.com"},
+            {"id": 2, "name": "李四", "email": "lisi@example.com"}
+        ],
+        "total": 2,
+        "page": 1,
+        "page_size": 20
+    }
+}`,
+			"item.json": `{
+    "code": 0,
+    "message": "success",
+    "data": {"id": 1, "name": "张三", "email": "zhangsan@example.com"}
+}`,
+			"create.json": `{
+    "name": "王五",
+    "email": "wangwu@example.com"
+}`,
+		},
+	},
+	{
+		Name:        "webhook-receiver",
+		Title:       "Webhook接收",
+		Description: "接收第三方回调并返回确认，附带一个向本机发送测试事件的请求",
+		Variables: []TemplateVariable{
+			{Name: "webhook_path", Description: "回调接口路径", Default: "{{base_path}}/webhook"},
+		},
+		config: Config{
+			Endpoints: []EndpointConfig{
+				{Name: "Webhook回调", Path: "{{webhook_path}}", ResponseFile: "webhook_ack.json"},
+			},
+			SendBlocks: []SendBlock{
+				{Name: "发送测试事件", URL: "http://127.0.0.1:{{port}}{{webhook_path}}", SendFile: "webhook_event.json", Method: "POST", Headers: `{"Content-Type": "application/json", "X-Event-Type": "order.paid"}`},
+			},
+		},
+		files: map[string]string{
+			"webhook_ack.json": `{
+    "code": 0,
+    "message": "received"
+}`,
+			"webhook_event.json": `{
+    "event": "order.paid",
+    "event_id": "evt_0001",
+    "created_at": "2024-01-01T12:00:00Z",
+    "data": {
+        "order_id": "ORD20240101001",
+        "amount": 9900,
+        "currency": "CNY"
+    }
+}`,
+		},
+	},
+	{
+		Name:        "audit-flow",
+		Title:       "审核任务流程",
+		Description: "接收审核任务下发(sendtask)返回受理结果(cctvresp)，并向回调地址上报审核结果(cctvreport)",
+		Variables: []TemplateVariable{
+			{Name: "target_url", Description: "审核任务下发的目标地址", Default: "http://127.0.0.1:{{port}}"},
+			{Name: "callback_url", Description: "审核结果上报地址", Default: "http://127.0.0.1:{{port}}{{base_path}}/report"},
+		},
+		config: Config{
+			Endpoints: []EndpointConfig{
+				{Name: "审核任务下发", Path: "{{base_path}}/sendtask", ResponseFile: "cctvresp.json"},
+				{Name: "审核结果接收", Path: "{{base_path}}/report", ResponseFile: "report_ack.json"},
+			},
+			SendBlocks: []SendBlock{
+				{Name: "下发审核任务", URL: "{{target_url}}{{base_path}}/sendtask", SendFile: "sendtask.json", Method: "POST", Headers: `{"Content-Type": "application/json"}`},
+				{Name: "上报审核结果", URL: "{{callback_url}}", SendFile: "cctvreport.json", Method: "POST", Headers: `{"Content-Type": "application/json"}`},
+			},
+		},
+		files: map[string]string{
+			"sendtask.json": `{
+    "task_id": "test-task-001",
+    "priority": 5,
+    "protype": "8",
+    "desc": "描述",
+    "videoFiles": [
+        {
+            "fileId": "170_3",
+            "fileUrl": "http://127.0.0.1:8000/download/003.mp4"
+        }
+    ]
+}`,
+			"cctvresp.json": `{
+    "error": "success",
+    "nonce": null,
+    "timestamp": null,
+    "sign": null,
+    "data": {
+        "audit_id": "2965120736916955136"
+    },
+    "timeMillis": null,
+    "error_code": "0",
+    "error_message": "成功",
+    "error_detail_message": "成功",
+    "app_id": null,
+    "audit_id": null
+}`,
+			"cctvreport.json": `{
+    "error": "SUCCESS",
+    "nonce": "f89b0ab0e5ae4241a03f03acab459778",
+    "timestamp": 1756876327050,
+    "data": {
+        "result": "REJECT",
+        "textRisks": [
+            {
+                "riskLabel1": "涉政",
+                "riskLabel2": "涉政组织",
+                "riskLabel3": "其他组织机构及政党",
+                "text": "示例文本",
+                "startTime": 1708,
+                "endTime": 1711,
+                "rate": 100.0,
+                "result": "REJECT",
+                "target": "语音识别"
+            }
+        ],
+        "imageRisks": [],
+        "asrResults": [
+            {
+                "text": "示例语音识别文本",
+                "startTime": 39,
+                "endTime": 42
+            }
+        ]
+    },
+    "error_code": "0",
+    "error_message": "成功",
+    "app_id": null,
+    "audit_id": "2965120736916955136",
+    "task_id": "test-task-001",
+    "sign": null
+}`,
+			"report_ack.json": `{
+    "code": 0,
+    "message": "success"
+}`,
+		},
+	},
+}
+
+func findBuiltinTemplate(name string) *BuiltinProjectTemplate {
+	for i := range builtinProjectTemplates {
+		if builtinProjectTemplates[i].Name == name {
+			return &builtinProjectTemplates[i]
+		}
+	}
+	return nil
+}
+
+// 合并变量：先用请求中的值，再按顺序补上默认值，默认值中的占位符用已有变量展开
+func resolveTemplateVariables(defs []TemplateVariable, given map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(given)+len(defs))
+	for name, value := range given {
+		if !templateVariableName.MatchString(name) {
+			return nil, fmt.Errorf("非法变量名: %s", name)
+		}
+		values[name] = value
+	}
+	for _, def := range defs {
+		if _, ok := values[def.Name]; !ok && def.Default != "" {
+			values[def.Name] = expandTemplateVariables(def.Default, values, false)
+		}
+	}
+	if port, ok := values["port"]; ok {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("端口无效: %s", port)
+		}
+	}
+	return values, nil
+}
+
+// 替换占位符，JSON文件中按字符串转义，避免值中的引号破坏文件
+func expandTemplateVariables(text string, values map[string]string, jsonEscape bool) string {
+	return templateVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := templateVariablePattern.FindStringSubmatch(match)[1]
+		value, ok := values[name]
+		if !ok {
+			return match
+		}
+		if jsonEscape {
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.Encode(value)
+			return strings.TrimSuffix(strings.TrimSpace(buf.String()), `"`)[1:]
+		}
+		return value
+	})
+}
+
+// 需要替换变量的项目文件：配置文件和响应文件目录下的文本文件
+func templateTextFiles(dir string) []string {
+	files := []string{filepath.Join(dir, "config.json")}
+	filepath.WalkDir(filepath.Join(dir, "json_files"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json", ".xml", ".txt", ".graphql":
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// 对项目目录中的配置和响应文件替换模板变量，替换后配置文件必须仍然有效。
+// port不为空时同时设置监听端口，已有项目和归档中的端口通常是写死的
+func applyTemplateVariables(dir string, values map[string]string, port string) error {
+	for _, path := range templateTextFiles(dir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if !templateVariablePattern.Match(data) {
+			continue
+		}
+		expanded := expandTemplateVariables(string(data), values, strings.EqualFold(filepath.Ext(path), ".json"))
+		if err := os.WriteFile(path, []byte(expanded), 0644); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("替换变量后配置文件无效: %v", err)
+	}
+	if port == "" || config.Port == port {
+		return nil
+	}
+	config.Port = port
+	data, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "config.json"), data, 0644)
+}
+
+// 找出项目中使用的变量，供界面提示填写
+func scanTemplateVariables(dir string) []string {
+	seen := make(map[string]bool)
+	for _, path := range templateTextFiles(dir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, match := range templateVariablePattern.FindAllSubmatch(data, -1) {
+			seen[string(match[1])] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 把内置模板写到项目目录，变量留待统一替换
+func (t *BuiltinProjectTemplate) writeTo(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "json_files"), 0755); err != nil {
+		return err
+	}
+	config := defaultProjectConfig()
+	config.Port = "{{port}}"
+	config.Endpoints = t.config.Endpoints
+	config.SendBlocks = t.config.SendBlocks
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), data, 0644); err != nil {
+		return err
+	}
+	for name, content := range t.files {
+		if err := os.WriteFile(filepath.Join(dir, "json_files", name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 按模板创建项目，先在临时目录中生成再改名，失败时不留下半成品
+func createProjectFromTemplate(c *gin.Context, name string, ref ProjectTemplateRef, given map[string]string) {
+	var defs []TemplateVariable
+	var source string
+	switch ref.Source {
+	case templateSourceBuiltin:
+		template := findBuiltinTemplate(ref.Name)
+		if template == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "内置模板不存在: " + ref.Name})
+			return
+		}
+		defs = template.Variables
+	case templateSourceProject:
+		if !isValidProjectName(ref.Name) || !projectExists(ref.Name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "模板项目不存在: " + ref.Name})
+			return
+		}
+		source = getProjectPath(ref.Name)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "模板来源只能是builtin或project"})
+		return
+	}
+
+	values, err := resolveTemplateVariables(append(append([]TemplateVariable{}, commonTemplateVariables...), defs...), given)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := given["project"]; !ok {
+		values["project"] = name
+	}
+
+	tmp, err := os.MkdirTemp("projects", ".template-")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	if source != "" {
+		// 模板项目是当前项目时先保存，使用最新的配置
+		if ref.Name == currentProject {
+			if err := saveConfig(); err != nil {
+				log.Printf("保存当前项目配置失败: %v", err)
+			}
+		}
+		err = copyProjectDir(source, tmp, projectCloneSkip)
+	} else {
+		err = findBuiltinTemplate(ref.Name).writeTo(tmp)
+	}
+	if err == nil {
+		err = applyTemplateVariables(tmp, values, given["port"])
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建项目失败: " + err.Error()})
+		return
+	}
+	if err := os.Rename(tmp, getProjectPath(name)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建项目失败: " + err.Error()})
+		return
+	}
+	if err := scheduler.loadPaused(name); err != nil {
+		log.Printf("加载项目 %s 的定时任务失败: %v", name, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "项目创建成功", "name": name, "variables": values})
+}
+
+// 上传归档作为模板创建项目，变量以JSON对象放在表单字段variables中
+func createProjectFromArchive(c *gin.Context) {
+	given := map[string]string{}
+	if raw := c.PostForm("variables"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &given); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "variables格式错误: " + err.Error()})
+			return
+		}
+	}
+	if c.PostForm("name") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目名不能为空"})
+		return
+	}
+	values, err := resolveTemplateVariables(commonTemplateVariables, given)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := given["project"]; !ok {
+		values["project"] = c.PostForm("name")
+	}
+	importUploadedProject(c, importConflictFail, values, given["port"])
+}
+
+// API: 列出可用的项目模板，包括内置模板和已有项目中使用的变量
+func listProjectTemplates(c *gin.Context) {
+	type projectTemplate struct {
+		Name      string   `json:"name"`
+		Variables []string `json:"variables"`
+	}
+	projects := []projectTemplate{}
+	entries, _ := os.ReadDir("projects")
+	for _, entry := range entries {
+		if !entry.IsDir() || !isValidProjectName(entry.Name()) || isProjectArchived(entry.Name()) {
+			continue
+		}
+		projects = append(projects, projectTemplate{
+			Name:      entry.Name(),
+			Variables: scanTemplateVariables(getProjectPath(entry.Name())),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"common_variables": commonTemplateVariables,
+		"builtin":          builtinProjectTemplates,
+		"projects":         projects,
+	})
+}
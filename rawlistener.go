@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 非HTTP监听的协议
+const (
+	rawProtocolTCP = "tcp"
+	rawProtocolUDP = "udp"
+)
+
+// TCP（按行分隔）或UDP监听配置，随模拟服务器一起启动和停止。
+// 匹配规则与WebSocket接口相同，没有规则匹配时回复ResponseFile
+type RawListenerConfig struct {
+	Name         string   `json:"name"`
+	Protocol     string   `json:"protocol"`
+	Port         string   `json:"port"`
+	ResponseFile string   `json:"response_file,omitempty"`
+	Rules        []WSRule `json:"rules,omitempty"`
+}
+
+type rawListenerSet struct {
+	mu        sync.Mutex
+	listeners []net.Listener
+	packets   []net.PacketConn
+	conns     map[net.Conn]bool
+}
+
+var rawListeners = &rawListenerSet{conns: make(map[net.Conn]bool)}
+
+// 启动所有监听，任意一个失败时关闭已启动的监听
+func (s *rawListenerSet) start(project, ip string, configs []RawListenerConfig) error {
+	for _, cfg := range configs {
+		addr := net.JoinHostPort(ip, cfg.Port)
+		handler := &rawHandler{project: project, config: cfg, rules: compileWSRules(cfg.Rules)}
+
+		switch cfg.Protocol {
+		case rawProtocolTCP:
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				s.stop()
+				return fmt.Errorf("TCP监听 %s 启动失败: %v", addr, err)
+			}
+			handler.path = "tcp://" + ln.Addr().String()
+			s.mu.Lock()
+			s.listeners = append(s.listeners, ln)
+			s.mu.Unlock()
+			go s.acceptTCP(ln, handler)
+		case rawProtocolUDP:
+			pc, err := net.ListenPacket("udp", addr)
+			if err != nil {
+				s.stop()
+				return fmt.Errorf("UDP监听 %s 启动失败: %v", addr, err)
+			}
+			handler.path = "udp://" + pc.LocalAddr().String()
+			s.mu.Lock()
+			s.packets = append(s.packets, pc)
+			s.mu.Unlock()
+			go handler.serveUDP(pc)
+		default:
+			s.stop()
+			return fmt.Errorf("不支持的监听协议: %s", cfg.Protocol)
+		}
+		log.Printf("%s监听启动在 %s", strings.ToUpper(cfg.Protocol), addr)
+	}
+	return nil
+}
+
+func (s *rawListenerSet) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ln := range s.listeners {
+		ln.Close()
+	}
+	for _, pc := range s.packets {
+		pc.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.listeners = nil
+	s.packets = nil
+	s.conns = make(map[net.Conn]bool)
+}
+
+func (s *rawListenerSet) acceptTCP(ln net.Listener, handler *rawHandler) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		go func() {
+			handler.serveTCP(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+type rawHandler struct {
+	project string
+	path    string
+	config  RawListenerConfig
+	rules   []compiledWSRule
+}
+
+func (h *rawHandler) log(method, remote, body string) {
+	appendRequestLog(RequestLog{
+		ID:        len(server.RequestLogs) + 1,
+		Path:      h.path,
+		Method:    method,
+		Headers:   map[string]interface{}{"Remote-Addr": []string{remote}},
+		Body:      body,
+		Timestamp: time.Now(),
+		Protocol:  h.config.Protocol,
+	})
+}
+
+// 按规则生成回复，没有匹配的规则时使用响应文件
+func (h *rawHandler) replies(message string) [][]byte {
+	var replies []WSMessage
+	for _, rule := range h.rules {
+		if rule.matches(message) {
+			replies = rule.Replies
+			break
+		}
+	}
+	if replies == nil && h.config.ResponseFile != "" {
+		replies = []WSMessage{{File: h.config.ResponseFile}}
+	}
+
+	var result [][]byte
+	for _, m := range replies {
+		if m.DelayMs > 0 {
+			time.Sleep(time.Duration(m.DelayMs) * time.Millisecond)
+		}
+		data, err := m.render(h.project, message)
+		if err != nil {
+			log.Printf("%s回复读取失败: %v", h.path, err)
+			continue
+		}
+		// 按行分隔的协议中JSON需要压缩为单行
+		result = append(result, compactJSON(data))
+	}
+	return result
+}
+
+func (h *rawHandler) serveTCP(conn net.Conn) {
+	remote := conn.RemoteAddr().String()
+	h.log("TCP-CONNECT", remote, "")
+	defer func() {
+		conn.Close()
+		h.log("TCP-CLOSE", remote, "")
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		message := strings.TrimSuffix(scanner.Text(), "\r")
+		if message == "" {
+			continue
+		}
+		h.log("TCP", remote, message)
+		for _, reply := range h.replies(message) {
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if _, err := conn.Write(append(reply, '\n')); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *rawHandler) serveUDP(pc net.PacketConn) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		message := string(buf[:n])
+		h.log("UDP", addr.String(), message)
+
+		go func(message string, addr net.Addr) {
+			for _, reply := range h.replies(message) {
+				if _, err := pc.WriteTo(reply, addr); err != nil {
+					log.Printf("%s回复失败: %v", h.path, err)
+				}
+			}
+		}(message, addr)
+	}
+}
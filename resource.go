@@ -0,0 +1,663 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const endpointTypeResource = "resource"
+
+// 持久化的资源数据保存在项目目录下，不出现在文件列表中
+const resourceStateDir = ".resources"
+
+// 分页和排序使用的保留查询参数，其余参数作为过滤条件
+var resourceReservedParams = map[string]bool{
+	"_page": true, "_limit": true, "_sort": true, "_order": true, "q": true,
+	"page": true, "page_size": true, "pageSize": true, "limit": true, "_seed": true,
+	"sort": true, "order": true, "_": true,
+}
+
+// CRUD资源配置。接口路径为资源的基础路径如/api/users，同时提供/api/users/:id。
+// SeedFile为初始数据，未设置时使用接口的ResponseFile；文件是数组时直接作为记录列表，
+// 是对象时取ItemsField（默认为第一个数组字段）作为记录列表，列表响应保持原对象的结构。
+// Persist为true时每次修改后保存，重启后继续使用修改后的数据
+type ResourceConfig struct {
+	SeedFile   string `json:"seed_file,omitempty"`
+	ItemsField string `json:"items_field,omitempty"`
+	IDField    string `json:"id_field,omitempty"`
+	Persist    bool   `json:"persist,omitempty"`
+}
+
+// 一个资源的内存数据
+type resourceState struct {
+	mu         sync.Mutex
+	config     ResourceConfig
+	seedFile   string
+	itemsField string
+	envelope   map[string]interface{}
+	items      []map[string]interface{}
+}
+
+var (
+	resourceStates   = make(map[string]*resourceState)
+	resourceStatesMu sync.Mutex
+)
+
+func resourceKey(project, path string) string {
+	return project + "|" + path
+}
+
+// 持久化文件名，由基础路径转换而来
+func resourceStatePath(project, path string) string {
+	name := strings.Trim(strings.NewReplacer("/", "_", ":", "", "*", "").Replace(path), "_")
+	if name == "" {
+		name = "root"
+	}
+	return filepath.Join(getProjectPath(project), resourceStateDir, name+".json")
+}
+
+func (config *ResourceConfig) idField() string {
+	if config.IDField == "" {
+		return "id"
+	}
+	return config.IDField
+}
+
+// 从种子数据中取出记录列表，对象形式的种子同时返回外层结构
+func splitResourceSeed(seed interface{}, itemsField string) ([]map[string]interface{}, map[string]interface{}, string, error) {
+	var list []interface{}
+	var envelope map[string]interface{}
+	switch v := seed.(type) {
+	case []interface{}:
+		list = v
+	case map[string]interface{}:
+		envelope = v
+		if itemsField == "" {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if _, ok := v[key].([]interface{}); ok {
+					itemsField = key
+					break
+				}
+			}
+		}
+		if itemsField == "" {
+			return nil, nil, "", fmt.Errorf("种子数据中没有数组字段")
+		}
+		var ok bool
+		if list, ok = v[itemsField].([]interface{}); !ok {
+			return nil, nil, "", fmt.Errorf("种子数据的%s字段不是数组", itemsField)
+		}
+	default:
+		return nil, nil, "", fmt.Errorf("种子数据必须是数组或对象")
+	}
+
+	items := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			items = append(items, object)
+		}
+	}
+	return items, envelope, itemsField, nil
+}
+
+// 读取种子数据，persist时优先使用保存的数据
+func loadResourceState(project, path, responseFile string, config ResourceConfig) (*resourceState, error) {
+	seedFile := config.SeedFile
+	if seedFile == "" {
+		seedFile = responseFile
+	}
+	state := &resourceState{config: config, seedFile: seedFile}
+
+	var data []byte
+	var err error
+	if config.Persist {
+		data, err = os.ReadFile(resourceStatePath(project, path))
+	}
+	if !config.Persist || err != nil {
+		if seedFile == "" {
+			state.items = []map[string]interface{}{}
+			return state, nil
+		}
+		if data, err = readProjectFile(project, seedFile); err != nil {
+			return nil, fmt.Errorf("读取种子数据失败: %v", err)
+		}
+	}
+
+	var seed interface{}
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return nil, fmt.Errorf("种子数据不是有效的JSON: %v", err)
+	}
+	items, envelope, itemsField, err := splitResourceSeed(seed, config.ItemsField)
+	if err != nil {
+		return nil, err
+	}
+	state.items, state.envelope, state.itemsField = items, envelope, itemsField
+	return state, nil
+}
+
+// 取资源的内存数据，首次访问或配置变化时重新加载
+func getResourceState(project, path, responseFile string, config ResourceConfig) (*resourceState, error) {
+	resourceStatesMu.Lock()
+	defer resourceStatesMu.Unlock()
+
+	key := resourceKey(project, path)
+	seedFile := config.SeedFile
+	if seedFile == "" {
+		seedFile = responseFile
+	}
+	if state, ok := resourceStates[key]; ok && state.config == config && state.seedFile == seedFile {
+		return state, nil
+	}
+	state, err := loadResourceState(project, path, responseFile, config)
+	if err != nil {
+		return nil, err
+	}
+	resourceStates[key] = state
+	return state, nil
+}
+
+// 保存修改后的数据，调用方持有state.mu
+func (state *resourceState) persist(project, path string) error {
+	if !state.config.Persist {
+		return nil
+	}
+	var data interface{} = state.items
+	if state.envelope != nil {
+		data = state.envelopeWith(state.items, len(state.items), 0, 0)
+	}
+	content, err := json.MarshalIndent(data, "", "    ")
+	if err == nil {
+		target := resourceStatePath(project, path)
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err == nil {
+			err = os.WriteFile(target, content, 0644)
+		}
+	}
+	return err
+}
+
+// 修改以内存数据为准，保存只是尽力而为：失败时记录日志并通过X-Persist-Error响应头告知调用方，不影响本次响应
+func (state *resourceState) save(c *gin.Context, path string) {
+	if err := state.persist(currentProject, path); err != nil {
+		log.Printf("保存资源数据失败 %s: %v", path, err)
+		c.Header("X-Persist-Error", err.Error())
+	}
+}
+
+// 按种子数据的外层结构组织列表响应，total、page等字段存在时一并更新
+func (state *resourceState) envelopeWith(items []map[string]interface{}, total, page, limit int) map[string]interface{} {
+	result := make(map[string]interface{}, len(state.envelope))
+	for key, value := range state.envelope {
+		result[key] = value
+	}
+	list := make([]interface{}, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	result[state.itemsField] = list
+	if _, ok := result["total"]; ok {
+		result["total"] = total
+	}
+	for _, key := range []string{"page", "pageNo", "page_no"} {
+		if _, ok := result[key]; ok && page > 0 {
+			result[key] = page
+		}
+	}
+	for _, key := range []string{"pageSize", "page_size", "limit"} {
+		if _, ok := result[key]; ok && limit > 0 {
+			result[key] = limit
+		}
+	}
+	return result
+}
+
+func (state *resourceState) find(id string) int {
+	field := state.config.idField()
+	for i, item := range state.items {
+		if resourceValueString(item[field]) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// 新记录的id：已有id都是数字时取最大值加一，否则生成uuid
+func (state *resourceState) nextID() interface{} {
+	field := state.config.idField()
+	max := 0.0
+	for _, item := range state.items {
+		switch id := item[field].(type) {
+		case float64:
+			if id > max {
+				max = id
+			}
+		case nil:
+		default:
+			return newFakeGenerator(randomFakeSeed(), nil).uuid()
+		}
+	}
+	return max + 1
+}
+
+// 取字段值，支持a.b形式的嵌套字段
+func resourceField(item map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = item
+	for _, part := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func resourceValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// 比较两个值，都是数字时按数值比较
+func compareResourceValues(a interface{}, b string) int {
+	if x, ok := a.(float64); ok {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(resourceValueString(a), b)
+}
+
+// 列表过滤条件
+type resourceFilter struct {
+	field  string
+	op     string
+	values []string
+}
+
+// 解析过滤条件：field=v（多个值为或）、field_ne、field_like、field_gte、field_lte。
+// 集合中没有任何记录含有的字段不作为过滤条件，前端常带的缓存参数如_=123不会把记录全部过滤掉
+func parseResourceFilters(items []map[string]interface{}, query map[string][]string) []resourceFilter {
+	var filters []resourceFilter
+	for key, values := range query {
+		if resourceReservedParams[key] {
+			continue
+		}
+		field, op := key, ""
+		for _, suffix := range []string{"_ne", "_like", "_gte", "_lte"} {
+			if strings.HasSuffix(key, suffix) {
+				field, op = strings.TrimSuffix(key, suffix), suffix
+				break
+			}
+		}
+		for _, item := range items {
+			if _, ok := resourceField(item, field); ok {
+				filters = append(filters, resourceFilter{field: field, op: op, values: values})
+				break
+			}
+		}
+	}
+	return filters
+}
+
+// 记录是否满足所有过滤条件，q为全文搜索
+func matchResource(item map[string]interface{}, filters []resourceFilter, q string) bool {
+	for _, filter := range filters {
+		value, ok := resourceField(item, filter.field)
+		if !ok && filter.op == "" {
+			return false
+		}
+		text := resourceValueString(value)
+		switch filter.op {
+		case "":
+			matched := false
+			for _, v := range filter.values {
+				if text == v {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		case "_ne":
+			for _, v := range filter.values {
+				if ok && text == v {
+					return false
+				}
+			}
+		case "_like":
+			for _, v := range filter.values {
+				if !ok || !strings.Contains(strings.ToLower(text), strings.ToLower(v)) {
+					return false
+				}
+			}
+		case "_gte":
+			for _, v := range filter.values {
+				if !ok || compareResourceValues(value, v) < 0 {
+					return false
+				}
+			}
+		case "_lte":
+			for _, v := range filter.values {
+				if !ok || compareResourceValues(value, v) > 0 {
+					return false
+				}
+			}
+		}
+	}
+	if q = strings.ToLower(q); q != "" {
+		data, _ := json.Marshal(item)
+		if !strings.Contains(strings.ToLower(string(data)), q) {
+			return false
+		}
+	}
+	return true
+}
+
+// 排序：_sort=a,-b，或配合_order=desc
+func sortResources(items []map[string]interface{}, sortParam, orderParam string) {
+	if sortParam == "" {
+		return
+	}
+	fields := strings.Split(sortParam, ",")
+	orders := strings.Split(orderParam, ",")
+	sort.SliceStable(items, func(i, j int) bool {
+		for k, field := range fields {
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			if k < len(orders) && strings.EqualFold(orders[k], "desc") {
+				desc = true
+			}
+			a, _ := resourceField(items[i], field)
+			b, _ := resourceField(items[j], field)
+			if cmp := compareResourceValues(a, resourceValueString(b)); cmp != 0 {
+				return cmp < 0 != desc
+			}
+		}
+		return false
+	})
+}
+
+func queryInt(c *gin.Context, keys ...string) int {
+	for _, key := range keys {
+		if n, err := strconv.Atoi(c.Query(key)); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// 按RFC 7386合并：null删除字段，对象递归合并
+func mergeResourcePatch(target map[string]interface{}, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			if targetObject, ok := target[key].(map[string]interface{}); ok {
+				mergeResourcePatch(targetObject, patchObject)
+				continue
+			}
+		}
+		target[key] = value
+	}
+}
+
+// 深拷贝记录，避免响应和内存数据共用同一个对象
+func copyResource(item map[string]interface{}) map[string]interface{} {
+	data, _ := json.Marshal(item)
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+	return result
+}
+
+func bindResourceBody(c *gin.Context, data string) (map[string]interface{}, bool) {
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &body); err != nil || body == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求体必须是JSON对象"})
+		return nil, false
+	}
+	return body, true
+}
+
+// CRUD资源接口，id为空时是集合操作
+func handleResourceEndpoint(c *gin.Context, path, responseFile string, config *ResourceConfig) {
+	requestLog := recordIncomingRequest(c, path)
+	if config == nil {
+		config = &ResourceConfig{}
+	}
+	state, err := getResourceState(currentProject, path, responseFile, *config)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	id := c.Param("id")
+	idField := state.config.idField()
+	method := c.Request.Method
+	if id == "" {
+		switch method {
+		case http.MethodGet, http.MethodHead:
+			state.list(c)
+		case http.MethodPost:
+			body, ok := bindResourceBody(c, requestLog.Body)
+			if !ok {
+				return
+			}
+			if body[idField] == nil {
+				body[idField] = state.nextID()
+			} else if state.find(resourceValueString(body[idField])) >= 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "记录已存在: " + resourceValueString(body[idField])})
+				return
+			}
+			state.items = append(state.items, body)
+			state.save(c, path)
+			c.JSON(http.StatusCreated, body)
+		default:
+			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "不支持的方法: " + method})
+		}
+		return
+	}
+
+	index := state.find(id)
+	if index < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "记录不存在: " + id})
+		return
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		c.JSON(http.StatusOK, state.items[index])
+	case http.MethodPut:
+		body, ok := bindResourceBody(c, requestLog.Body)
+		if !ok {
+			return
+		}
+		// 整体替换，id保持不变
+		body[idField] = state.items[index][idField]
+		state.items[index] = body
+		state.save(c, path)
+		c.JSON(http.StatusOK, body)
+	case http.MethodPatch:
+		body, ok := bindResourceBody(c, requestLog.Body)
+		if !ok {
+			return
+		}
+		delete(body, idField)
+		mergeResourcePatch(state.items[index], body)
+		state.save(c, path)
+		c.JSON(http.StatusOK, state.items[index])
+	case http.MethodDelete:
+		state.items = append(state.items[:index], state.items[index+1:]...)
+		state.save(c, path)
+		c.Status(http.StatusNoContent)
+	default:
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "不支持的方法: " + method})
+	}
+}
+
+// 列表：过滤、排序后分页，总数放在X-Total-Count响应头
+func (state *resourceState) list(c *gin.Context) {
+	query := c.Request.URL.Query()
+	filters := parseResourceFilters(state.items, query)
+	items := make([]map[string]interface{}, 0, len(state.items))
+	for _, item := range state.items {
+		if matchResource(item, filters, strings.Join(query["q"], "")) {
+			items = append(items, copyResource(item))
+		}
+	}
+	sortResources(items, c.Query("_sort"), c.Query("_order"))
+
+	total := len(items)
+	page := queryInt(c, "_page", "page")
+	limit := queryInt(c, "_limit", "page_size", "pageSize", "limit")
+	if page > 0 && limit == 0 {
+		limit = 10
+	}
+	if limit > 0 {
+		if page == 0 {
+			page = 1
+		}
+		// 先比较页号再相乘，避免超大的页号或每页条数溢出
+		start, end := total, total
+		if page-1 <= total/limit {
+			start = (page - 1) * limit
+			if start > total {
+				start = total
+			}
+		}
+		if limit < total-start {
+			end = start + limit
+		}
+		items = items[start:end]
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.Header("Access-Control-Expose-Headers", "X-Total-Count")
+	if state.envelope != nil {
+		c.JSON(http.StatusOK, state.envelopeWith(items, total, page, limit))
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// 当前项目的资源接口
+func currentResourceEndpoints() []EndpointConfig {
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+	var endpoints []EndpointConfig
+	for _, endpoint := range server.Endpoints {
+		if endpoint.Type == endpointTypeResource {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// API: 当前项目的资源接口及记录数
+func listResources(c *gin.Context) {
+	result := []gin.H{}
+	for _, endpoint := range currentResourceEndpoints() {
+		path := normalizeResourcePath(endpoint.Path)
+		config := ResourceConfig{}
+		if endpoint.Resource != nil {
+			config = *endpoint.Resource
+		}
+		item := gin.H{"path": path, "name": endpoint.Name, "persist": config.Persist}
+		if state, err := getResourceState(currentProject, path, endpoint.ResponseFile, config); err != nil {
+			item["error"] = err.Error()
+		} else {
+			state.mu.Lock()
+			item["count"] = len(state.items)
+			state.mu.Unlock()
+		}
+		result = append(result, item)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// API: 把资源恢复为种子数据，不指定path时恢复当前项目的所有资源
+func resetResources(c *gin.Context) {
+	var request struct {
+		Path string `json:"path"`
+	}
+	c.ShouldBindJSON(&request)
+	target := ""
+	if request.Path != "" {
+		target = normalizeResourcePath(request.Path)
+	}
+
+	var reset []string
+	for _, endpoint := range currentResourceEndpoints() {
+		path := normalizeResourcePath(endpoint.Path)
+		if target != "" && path != target {
+			continue
+		}
+		resourceStatesMu.Lock()
+		delete(resourceStates, resourceKey(currentProject, path))
+		resourceStatesMu.Unlock()
+		if err := os.Remove(resourceStatePath(currentProject, path)); err != nil && !os.IsNotExist(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "删除保存的资源数据失败: " + err.Error()})
+			return
+		}
+		reset = append(reset, path)
+	}
+	if target != "" && len(reset) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "资源接口不存在: " + target})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("已重置%d个资源", len(reset)), "paths": reset})
+}
+
+func normalizeResourcePath(path string) string {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// 注册资源的集合路由和单条记录路由
+func registerResourceEndpoint(engine *gin.Engine, endpoint EndpointConfig) {
+	path := normalizeResourcePath(endpoint.Path)
+	responseFile := endpoint.ResponseFile
+	config := endpoint.Resource
+	handler := func(c *gin.Context) {
+		handleResourceEndpoint(c, path, responseFile, config)
+	}
+	engine.Any(path, handler)
+	engine.Any(strings.TrimSuffix(path, "/")+"/:id", handler)
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 项目的版本历史目录，内容按哈希存放，同样的内容只保存一份
+const projectHistoryDir = ".history"
+
+// 每个文件最多保留的版本数
+const maxFileRevisions = 50
+
+// 超过该大小的文件不记录版本，避免大的二进制样本占满磁盘
+const maxVersionedFileSize = 5 << 20
+
+// 版本来源
+const (
+	revisionActionSave    = "save"
+	revisionActionRestore = "restore"
+	// 工具外修改或首次纳入历史的内容，覆盖前先保存下来
+	revisionActionExternal = "external"
+)
+
+// 配置文件或响应文件的一个版本，File为相对项目目录的路径
+type FileRevision struct {
+	ID     int       `json:"id"`
+	File   string    `json:"file"`
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Size   int       `json:"size"`
+	Hash   string    `json:"hash"`
+}
+
+// 版本索引和对象文件的读写都在锁内进行
+var projectRevisions sync.Mutex
+
+func getHistoryPath(project string) string {
+	return filepath.Join(getProjectPath(project), projectHistoryDir)
+}
+
+func revisionObjectPath(project, hash string) string {
+	return filepath.Join(getHistoryPath(project), "objects", hash)
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// 调用方需持有projectRevisions
+func loadRevisions(project string) []FileRevision {
+	revisions := []FileRevision{}
+	data, err := os.ReadFile(filepath.Join(getHistoryPath(project), "index.json"))
+	if err != nil {
+		return revisions
+	}
+	if err := json.Unmarshal(data, &revisions); err != nil {
+		log.Printf("项目 %s 的版本索引损坏: %v", project, err)
+		return []FileRevision{}
+	}
+	return revisions
+}
+
+// 调用方需持有projectRevisions
+func saveRevisions(project string, revisions []FileRevision) error {
+	data, err := json.MarshalIndent(revisions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getHistoryPath(project), "index.json"), data, 0644)
+}
+
+func latestRevision(revisions []FileRevision, file string) *FileRevision {
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].File == file {
+			return &revisions[i]
+		}
+	}
+	return nil
+}
+
+// 追加一个版本，内容与文件最新版本相同时不记录
+func appendRevision(project string, revisions []FileRevision, file, action string, data []byte) ([]FileRevision, error) {
+	if len(data) > maxVersionedFileSize {
+		return revisions, nil
+	}
+	hash := contentHash(data)
+	if last := latestRevision(revisions, file); last != nil && last.Hash == hash {
+		return revisions, nil
+	}
+	objectPath := revisionObjectPath(project, hash)
+	if _, err := os.Stat(objectPath); err != nil {
+		if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+			return revisions, err
+		}
+		if err := os.WriteFile(objectPath, data, 0644); err != nil {
+			return revisions, err
+		}
+	}
+	id := 1
+	if len(revisions) > 0 {
+		id = revisions[len(revisions)-1].ID + 1
+	}
+	return append(revisions, FileRevision{
+		ID:     id,
+		File:   file,
+		Time:   time.Now(),
+		Action: action,
+		Size:   len(data),
+		Hash:   hash,
+	}), nil
+}
+
+// 每个文件只保留最近的版本，删除不再被引用的内容
+func pruneRevisions(project string, revisions []FileRevision) []FileRevision {
+	counts := make(map[string]int)
+	kept := make([]FileRevision, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		counts[revisions[i].File]++
+		if counts[revisions[i].File] <= maxFileRevisions {
+			kept = append(kept, revisions[i])
+		}
+	}
+	if len(kept) == len(revisions) {
+		return revisions
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].ID < kept[j].ID })
+	referenced := make(map[string]bool)
+	for _, revision := range kept {
+		referenced[revision.Hash] = true
+	}
+	for _, revision := range revisions {
+		if !referenced[revision.Hash] {
+			os.Remove(revisionObjectPath(project, revision.Hash))
+		}
+	}
+	return kept
+}
+
+// 写入项目文件并记录版本。文件在工具外被改过或还没有历史时，先把现有内容存为一个版本。
+// 历史记录失败只打印日志，不影响文件保存
+func writeVersionedFile(project, file string, data []byte, action string) error {
+	projectRevisions.Lock()
+	defer projectRevisions.Unlock()
+	return writeVersionedFileLocked(project, file, data, action)
+}
+
+func writeVersionedFileLocked(project, file string, data []byte, action string) error {
+	path := filepath.Join(getProjectPath(project), filepath.FromSlash(file))
+	revisions := loadRevisions(project)
+	var historyErr error
+	if current, err := os.ReadFile(path); err == nil {
+		revisions, historyErr = appendRevision(project, revisions, file, revisionActionExternal, current)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	if historyErr == nil {
+		revisions, historyErr = appendRevision(project, revisions, file, action, data)
+	}
+	if historyErr == nil {
+		historyErr = saveRevisions(project, pruneRevisions(project, revisions))
+	}
+	if historyErr != nil {
+		log.Printf("记录 %s/%s 的版本失败: %v", project, file, historyErr)
+	}
+	return nil
+}
+
+// 删除项目文件，删除前的内容没有记录过时先存为一个版本，之后可以从历史恢复
+func removeVersionedFile(project, file string) error {
+	projectRevisions.Lock()
+	defer projectRevisions.Unlock()
+
+	path := filepath.Join(getProjectPath(project), filepath.FromSlash(file))
+	if current, err := os.ReadFile(path); err == nil {
+		revisions, err := appendRevision(project, loadRevisions(project), file, revisionActionExternal, current)
+		if err == nil {
+			err = saveRevisions(project, pruneRevisions(project, revisions))
+		}
+		if err != nil {
+			log.Printf("记录 %s/%s 的版本失败: %v", project, file, err)
+		}
+	}
+	return os.Remove(path)
+}
+
+func readRevisionContent(project string, revision FileRevision) ([]byte, error) {
+	return os.ReadFile(revisionObjectPath(project, revision.Hash))
+}
+
+// 调用方需持有projectRevisions
+func findRevision(project string, id int) (FileRevision, bool) {
+	for _, revision := range loadRevisions(project) {
+		if revision.ID == id {
+			return revision, true
+		}
+	}
+	return FileRevision{}, false
+}
+
+// 恢复config.json后重新加载当前项目的配置
+func reloadRestoredConfig(files []string) {
+	for _, file := range files {
+		if file == "config.json" {
+			if err := loadConfig(); err != nil {
+				log.Printf("重新加载配置失败: %v", err)
+			}
+			broadcastToClients(map[string]interface{}{"type": "status_update", "data": server})
+			return
+		}
+	}
+}
+
+// API: 查询当前项目的版本历史，可按文件过滤，按时间倒序
+func listRevisions(c *gin.Context) {
+	file := c.Query("file")
+	projectRevisions.Lock()
+	revisions := loadRevisions(currentProject)
+	projectRevisions.Unlock()
+
+	result := []FileRevision{}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if file == "" || revisions[i].File == file {
+			result = append(result, revisions[i])
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": result})
+}
+
+// API: 查看某个版本的内容
+func getRevision(c *gin.Context) {
+	id, ok := historyIDParam(c, "id")
+	if !ok {
+		return
+	}
+	projectRevisions.Lock()
+	revision, found := findRevision(currentProject, id)
+	projectRevisions.Unlock()
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "版本不存在"})
+		return
+	}
+	data, err := readRevisionContent(currentProject, revision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取版本内容失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revision": revision, "content": string(data)})
+}
+
+// API: 对比同一文件的两个版本，to为current或不填时与当前文件对比
+func diffRevisions(c *gin.Context) {
+	fromID, ok := historyIDParam(c, "from")
+	if !ok {
+		return
+	}
+
+	projectRevisions.Lock()
+	defer projectRevisions.Unlock()
+	from, found := findRevision(currentProject, fromID)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "版本不存在"})
+		return
+	}
+	fromData, err := readRevisionContent(currentProject, from)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取版本内容失败: " + err.Error()})
+		return
+	}
+
+	var to interface{} = "current"
+	var toData []byte
+	if c.Query("to") == "" || c.Query("to") == "current" {
+		toData, err = os.ReadFile(filepath.Join(getProjectPath(currentProject), filepath.FromSlash(from.File)))
+		if err != nil && !os.IsNotExist(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		toID, ok := historyIDParam(c, "to")
+		if !ok {
+			return
+		}
+		revision, found := findRevision(currentProject, toID)
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "版本不存在"})
+			return
+		}
+		if revision.File != from.File {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "只能对比同一文件的版本"})
+			return
+		}
+		if toData, err = readRevisionContent(currentProject, revision); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "读取版本内容失败: " + err.Error()})
+			return
+		}
+		to = revision
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":  from.File,
+		"from":  from,
+		"to":    to,
+		"lines": diffLines(string(fromData), string(toData)),
+	})
+}
+
+// API: 把单个文件恢复到指定版本，恢复本身也记录为新版本
+func restoreRevision(c *gin.Context) {
+	id, ok := historyIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	projectRevisions.Lock()
+	revision, found := findRevision(currentProject, id)
+	if !found {
+		projectRevisions.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "版本不存在"})
+		return
+	}
+	data, err := readRevisionContent(currentProject, revision)
+	if err == nil {
+		err = writeVersionedFileLocked(currentProject, revision.File, data, revisionActionRestore)
+	}
+	projectRevisions.Unlock()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复失败: " + err.Error()})
+		return
+	}
+
+	reloadRestoredConfig([]string{revision.File})
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s 已恢复到版本%d", revision.File, revision.ID)})
+}
+
+// API: 把整个项目恢复到指定版本时的状态，之后才创建的文件保持不变
+func restoreProjectRevision(c *gin.Context) {
+	var request struct {
+		Revision int `json:"revision"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	projectRevisions.Lock()
+	revisions := loadRevisions(currentProject)
+	if _, found := findRevision(currentProject, request.Revision); !found {
+		projectRevisions.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "版本不存在"})
+		return
+	}
+	// 每个文件在该版本时的最新内容
+	target := make(map[string]FileRevision)
+	for _, revision := range revisions {
+		if revision.ID <= request.Revision {
+			target[revision.File] = revision
+		}
+	}
+
+	restored := []string{}
+	var restoreErr error
+	for file, revision := range target {
+		current, err := os.ReadFile(filepath.Join(getProjectPath(currentProject), filepath.FromSlash(file)))
+		if err == nil && contentHash(current) == revision.Hash {
+			continue
+		}
+		data, err := readRevisionContent(currentProject, revision)
+		if err == nil {
+			err = writeVersionedFileLocked(currentProject, file, data, revisionActionRestore)
+		}
+		if err != nil {
+			restoreErr = fmt.Errorf("%s: %v", file, err)
+			break
+		}
+		restored = append(restored, file)
+	}
+	projectRevisions.Unlock()
+
+	sort.Strings(restored)
+	reloadRestoredConfig(restored)
+	if restoreErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复失败: " + restoreErr.Error(), "restored": restored})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  fmt.Sprintf("项目已恢复到版本%d，共恢复%d个文件", request.Revision, len(restored)),
+		"restored": restored,
+	})
+}
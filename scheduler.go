@@ -0,0 +1,577 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 定时任务状态
+const (
+	jobStatusRunning = "running"
+	jobStatusPaused  = "paused"
+	jobStatusStopped = "stopped"
+)
+
+// 每个项目最多保留的任务执行记录数
+const maxJobHistory = 1000
+
+// 定时发送任务，按cron表达式或固定间隔依次发送一组发送块
+type ScheduledJob struct {
+	ID              int         `json:"id"`
+	Name            string      `json:"name"`
+	Blocks          []SendBlock `json:"blocks"`
+	Cron            string      `json:"cron"`
+	IntervalSeconds int         `json:"interval_seconds"`
+	MaxRuns         int         `json:"max_runs"`
+	Status          string      `json:"status"`
+	RunCount        int         `json:"run_count"`
+	CreatedAt       time.Time   `json:"created_at"`
+	LastRunAt       *time.Time  `json:"last_run_at,omitempty"`
+	NextRunAt       *time.Time  `json:"next_run_at,omitempty"`
+
+	project string
+	cron    *cronSchedule
+	stop    chan struct{}
+}
+
+// 任务单次执行记录
+type JobRun struct {
+	JobID     int           `json:"job_id"`
+	JobName   string        `json:"job_name"`
+	StartedAt time.Time     `json:"started_at"`
+	Steps     []JobRunStep  `json:"steps"`
+	Success   bool          `json:"success"`
+	Duration  time.Duration `json:"duration_ns"`
+}
+
+type JobRunStep struct {
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	Status     int     `json:"status,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type jobScheduler struct {
+	mu      sync.Mutex
+	jobs    map[string][]*ScheduledJob
+	history map[string][]JobRun
+	nextID  map[string]int
+}
+
+var scheduler = &jobScheduler{
+	jobs:    make(map[string][]*ScheduledJob),
+	history: make(map[string][]JobRun),
+	nextID:  make(map[string]int),
+}
+
+func getJobsPath(project string) string {
+	return filepath.Join(getProjectPath(project), "jobs.json")
+}
+
+func getJobHistoryPath(project string) string {
+	return filepath.Join(getProjectPath(project), "job_history.json")
+}
+
+// 加载所有项目的定时任务，并恢复之前处于运行状态的任务
+func initScheduler() error {
+	entries, err := os.ReadDir("projects")
+	if err != nil {
+		return err
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		project := entry.Name()
+		if strings.HasPrefix(project, ".") || isProjectArchived(project) {
+			continue
+		}
+		if err := scheduler.loadProject(project); err != nil {
+			log.Printf("加载项目 %s 的定时任务失败: %v", project, err)
+			continue
+		}
+		for _, job := range scheduler.jobs[project] {
+			if job.Status == jobStatusRunning {
+				scheduler.startJob(job)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jobScheduler) loadProject(project string) error {
+	var jobs []*ScheduledJob
+	if data, err := os.ReadFile(getJobsPath(project)); err == nil {
+		if err := json.Unmarshal(data, &jobs); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	var history []JobRun
+	if data, err := os.ReadFile(getJobHistoryPath(project)); err == nil {
+		if err := json.Unmarshal(data, &history); err != nil {
+			log.Printf("项目 %s 的任务历史解析失败: %v", project, err)
+		}
+	}
+
+	maxID := 0
+	for _, job := range jobs {
+		job.project = project
+		if job.Cron != "" {
+			cron, err := parseCron(job.Cron)
+			if err != nil {
+				return fmt.Errorf("任务 %s: %v", job.Name, err)
+			}
+			job.cron = cron
+		}
+		if job.ID > maxID {
+			maxID = job.ID
+		}
+	}
+
+	s.jobs[project] = jobs
+	s.history[project] = history
+	s.nextID[project] = maxID
+	return nil
+}
+
+// 停止项目的所有任务并移出调度器，任务文件中的状态保持不变
+func (s *jobScheduler) unloadProject(project string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs[project] {
+		s.haltJob(job, job.Status)
+	}
+	delete(s.jobs, project)
+	delete(s.history, project)
+	delete(s.nextID, project)
+}
+
+// 从任务文件重新加载项目并恢复运行中的任务
+func (s *jobScheduler) reloadProject(project string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadProject(project); err != nil {
+		return err
+	}
+	for _, job := range s.jobs[project] {
+		if job.Status == jobStatusRunning {
+			s.startJob(job)
+		}
+	}
+	return nil
+}
+
+// 加载克隆或导入的项目，运行中的任务改为暂停，避免与源项目重复发送
+func (s *jobScheduler) loadPaused(project string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadProject(project); err != nil {
+		return err
+	}
+	paused := false
+	for _, job := range s.jobs[project] {
+		if job.Status == jobStatusRunning {
+			job.Status = jobStatusPaused
+			paused = true
+		}
+	}
+	if !paused {
+		return nil
+	}
+	return s.saveJobs(project)
+}
+
+// 调用方需持有s.mu
+func (s *jobScheduler) saveJobs(project string) error {
+	data, err := json.MarshalIndent(s.jobs[project], "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getJobsPath(project), data, 0644)
+}
+
+// 调用方需持有s.mu
+func (s *jobScheduler) saveHistory(project string) error {
+	data, err := json.MarshalIndent(s.history[project], "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getJobHistoryPath(project), data, 0644)
+}
+
+// 调用方需持有s.mu
+func (s *jobScheduler) findJob(project string, id int) *ScheduledJob {
+	for _, job := range s.jobs[project] {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// 计算下一次触发时间
+func (job *ScheduledJob) nextRun(after time.Time) time.Time {
+	if job.cron != nil {
+		return job.cron.next(after)
+	}
+	return after.Add(time.Duration(job.IntervalSeconds) * time.Second)
+}
+
+// 启动任务的调度协程，调用方需持有s.mu
+func (s *jobScheduler) startJob(job *ScheduledJob) {
+	job.Status = jobStatusRunning
+	job.stop = make(chan struct{})
+	go s.runLoop(job, job.stop)
+}
+
+// 停止任务的调度协程，调用方需持有s.mu
+func (s *jobScheduler) haltJob(job *ScheduledJob, status string) {
+	if job.stop != nil {
+		close(job.stop)
+		job.stop = nil
+	}
+	job.Status = status
+	job.NextRunAt = nil
+}
+
+func (s *jobScheduler) runLoop(job *ScheduledJob, stop chan struct{}) {
+	var last time.Time
+	for {
+		s.mu.Lock()
+		// 固定间隔按上次计划时间推算，避免请求耗时造成漂移
+		base := time.Now()
+		if job.cron == nil && !last.IsZero() {
+			base = last
+		}
+		next := job.nextRun(base)
+		if !next.IsZero() && next.Before(time.Now()) {
+			next = time.Now()
+		}
+		last = next
+		if next.IsZero() {
+			s.haltJob(job, jobStatusStopped)
+			s.saveJobs(job.project)
+			s.mu.Unlock()
+			return
+		}
+		job.NextRunAt = &next
+		snapshot := *job
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		run := executeJob(&snapshot)
+
+		s.mu.Lock()
+		// 执行期间任务可能已被暂停或删除
+		select {
+		case <-stop:
+			s.mu.Unlock()
+			return
+		default:
+		}
+		job.RunCount++
+		job.LastRunAt = &run.StartedAt
+		s.history[job.project] = append(s.history[job.project], run)
+		if len(s.history[job.project]) > maxJobHistory {
+			s.history[job.project] = s.history[job.project][len(s.history[job.project])-maxJobHistory:]
+		}
+		if err := s.saveHistory(job.project); err != nil {
+			log.Printf("保存任务历史失败: %v", err)
+		}
+		finished := job.MaxRuns > 0 && job.RunCount >= job.MaxRuns
+		if finished {
+			s.haltJob(job, jobStatusStopped)
+		}
+		s.saveJobs(job.project)
+		s.mu.Unlock()
+
+		broadcastToClients(map[string]interface{}{"type": "job_run", "data": run})
+		if finished {
+			return
+		}
+	}
+}
+
+// 依次发送任务中的所有发送块
+func executeJob(job *ScheduledJob) JobRun {
+	run := JobRun{
+		JobID:     job.ID,
+		JobName:   job.Name,
+		StartedAt: time.Now(),
+		Success:   true,
+	}
+
+	for _, block := range job.Blocks {
+		step := JobRunStep{Name: block.Name, URL: block.URL}
+		start := time.Now()
+
+		req, err := buildSendRequestFromBlock(job.project, block, "")
+		var client *http.Client
+		if err == nil {
+			client, err = buildHTTPClient(job.project, effectiveTransport(job.project, block.Transport), 1)
+		}
+		if err == nil {
+			var result *SendResult
+			result, err = executeSendRequest(client, job.project, req, true)
+			sendHistory.record(job.project, sendSourceJob, req, result, err)
+			if err == nil {
+				step.Status = result.Status
+				if result.Status >= 400 {
+					run.Success = false
+				}
+			}
+		}
+		if err != nil {
+			step.Error = err.Error()
+			run.Success = false
+		}
+
+		step.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		run.Steps = append(run.Steps, step)
+	}
+
+	run.Duration = time.Since(run.StartedAt)
+	return run
+}
+
+func validateJob(job *ScheduledJob) error {
+	if len(job.Blocks) == 0 {
+		return errors.New("任务至少需要一个发送块")
+	}
+	if job.Cron == "" && job.IntervalSeconds <= 0 {
+		return errors.New("请指定cron表达式或执行间隔")
+	}
+	job.cron = nil
+	if job.Cron != "" {
+		cron, err := parseCron(job.Cron)
+		if err != nil {
+			return err
+		}
+		job.cron = cron
+	}
+	return nil
+}
+
+func jobIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法任务ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// API: 列出当前项目的定时任务
+func listJobs(c *gin.Context) {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	jobs := scheduler.jobs[currentProject]
+	if jobs == nil {
+		jobs = []*ScheduledJob{}
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// API: 创建定时任务
+func createJob(c *gin.Context) {
+	var job ScheduledJob
+	if err := c.ShouldBindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateJob(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	project := currentProject
+	scheduler.nextID[project]++
+	job.ID = scheduler.nextID[project]
+	job.project = project
+	job.Status = jobStatusStopped
+	job.RunCount = 0
+	job.CreatedAt = time.Now()
+	job.LastRunAt = nil
+	job.NextRunAt = nil
+	scheduler.jobs[project] = append(scheduler.jobs[project], &job)
+
+	if err := scheduler.saveJobs(project); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存任务失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// API: 修改定时任务，运行中的任务需要先停止
+func updateJob(c *gin.Context) {
+	id, ok := jobIDParam(c)
+	if !ok {
+		return
+	}
+
+	var update ScheduledJob
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateJob(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	job := scheduler.findJob(currentProject, id)
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	if job.Status == jobStatusRunning {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请先暂停或停止任务"})
+		return
+	}
+
+	job.Name = update.Name
+	job.Blocks = update.Blocks
+	job.Cron = update.Cron
+	job.IntervalSeconds = update.IntervalSeconds
+	job.MaxRuns = update.MaxRuns
+	job.cron = update.cron
+	scheduler.saveJobs(currentProject)
+
+	c.JSON(http.StatusOK, job)
+}
+
+// API: 删除定时任务
+func deleteJob(c *gin.Context) {
+	id, ok := jobIDParam(c)
+	if !ok {
+		return
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	jobs := scheduler.jobs[currentProject]
+	for i, job := range jobs {
+		if job.ID == id {
+			scheduler.haltJob(job, jobStatusStopped)
+			scheduler.jobs[currentProject] = append(jobs[:i], jobs[i+1:]...)
+			scheduler.saveJobs(currentProject)
+			c.JSON(http.StatusOK, gin.H{"message": "任务已删除"})
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+}
+
+// API: 启动任务（从暂停状态恢复时保留执行次数）
+func startJobHandler(c *gin.Context) {
+	id, ok := jobIDParam(c)
+	if !ok {
+		return
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	job := scheduler.findJob(currentProject, id)
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	if job.Status == jobStatusRunning {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "任务已在运行"})
+		return
+	}
+	if job.Status == jobStatusStopped {
+		job.RunCount = 0
+	}
+	scheduler.startJob(job)
+	scheduler.saveJobs(currentProject)
+
+	c.JSON(http.StatusOK, gin.H{"message": "任务已启动"})
+}
+
+// API: 暂停任务
+func pauseJob(c *gin.Context) {
+	changeJobStatus(c, jobStatusPaused, "任务已暂停")
+}
+
+// API: 停止任务
+func stopJob(c *gin.Context) {
+	changeJobStatus(c, jobStatusStopped, "任务已停止")
+}
+
+func changeJobStatus(c *gin.Context, status, message string) {
+	id, ok := jobIDParam(c)
+	if !ok {
+		return
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	job := scheduler.findJob(currentProject, id)
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	if status == jobStatusPaused && job.Status != jobStatusRunning {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "任务未运行"})
+		return
+	}
+	scheduler.haltJob(job, status)
+	scheduler.saveJobs(currentProject)
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// API: 查询当前项目的任务执行历史，可按任务ID过滤
+func getJobHistory(c *gin.Context) {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	history := scheduler.history[currentProject]
+	idParam := c.Param("id")
+
+	result := []JobRun{}
+	for i := len(history) - 1; i >= 0; i-- {
+		if idParam == "" || strconv.Itoa(history[i].JobID) == idParam {
+			result = append(result, history[i])
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// 请求体校验最多记录的错误数
+const maxSchemaErrors = 20
+
+// 请求体的JSON Schema校验配置，File为项目文件目录下的schema文件。
+// Reject为true时校验失败返回400，响应体为ErrorFile，未配置时返回错误列表
+type RequestSchemaConfig struct {
+	File      string `json:"file"`
+	Reject    bool   `json:"reject,omitempty"`
+	ErrorFile string `json:"error_file,omitempty"`
+}
+
+// 记录在请求日志中的校验结果
+type SchemaValidationResult struct {
+	Schema string   `json:"schema"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// 编译好的schema及编译时读取的文件（含$ref引用的文件）和修改时间
+type compiledRequestSchema struct {
+	schema *jsonschema.Schema
+	files  map[string]time.Time
+}
+
+// 按项目和文件名缓存编译结果，避免每个请求都重新读取和编译
+var (
+	requestSchemaCache   = make(map[string]*compiledRequestSchema)
+	requestSchemaCacheMu sync.Mutex
+)
+
+// 读取的文件都没有修改时可以继续使用
+func (c *compiledRequestSchema) fresh() bool {
+	for file, modTime := range c.files {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// 取编译好的schema，schema或其引用的文件修改后重新编译
+func cachedRequestSchema(project, name string) (*jsonschema.Schema, error) {
+	key := project + "|" + name
+	requestSchemaCacheMu.Lock()
+	cached := requestSchemaCache[key]
+	requestSchemaCacheMu.Unlock()
+	if cached != nil && cached.fresh() {
+		return cached.schema, nil
+	}
+
+	files := make(map[string]time.Time)
+	schema, err := compileRequestSchema(project, name, files)
+	if err != nil {
+		return nil, err
+	}
+	requestSchemaCacheMu.Lock()
+	requestSchemaCache[key] = &compiledRequestSchema{schema: schema, files: files}
+	requestSchemaCacheMu.Unlock()
+	return schema, nil
+}
+
+// 编译项目中的schema，$ref只能引用项目文件目录下的其他文件，读取的文件及修改时间记录到files
+func compileRequestSchema(project, name string, files map[string]time.Time) (*jsonschema.Schema, error) {
+	schemaPath, err := resolveProjectFile(project, name)
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(getJSONFilesPath(project))
+	if err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme != "file" {
+			return nil, fmt.Errorf("只能引用项目内的schema文件: %s", s)
+		}
+		target := filepath.Clean(filepath.FromSlash(u.Path))
+		if !strings.HasPrefix(target, root+string(filepath.Separator)) {
+			return nil, fmt.Errorf("只能引用项目内的schema文件: %s", s)
+		}
+		file, err := os.Open(target)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := file.Stat(); err == nil {
+			files[target] = info.ModTime()
+		}
+		return file, nil
+	}
+	return compiler.Compile((&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String())
+}
+
+// 展开校验错误，只保留最具体的原因
+func schemaErrorMessages(err *jsonschema.ValidationError) []string {
+	var messages []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			messages = append(messages, location+": "+e.Message)
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(err)
+	sort.Strings(messages)
+	if len(messages) > maxSchemaErrors {
+		messages = append(messages[:maxSchemaErrors], fmt.Sprintf("……共%d个错误", len(messages)))
+	}
+	return messages
+}
+
+// 按配置校验请求体，schema本身有问题时也记为校验失败
+func validateRequestBody(project string, config *RequestSchemaConfig, body string) *SchemaValidationResult {
+	result := &SchemaValidationResult{Schema: config.File, Valid: true}
+	schema, err := cachedRequestSchema(project, config.File)
+	if err != nil {
+		result.Valid = false
+		result.Errors = []string{"schema无效: " + err.Error()}
+		return result
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		result.Valid = false
+		result.Errors = []string{"请求体不是有效的JSON: " + err.Error()}
+		return result
+	}
+
+	if err := schema.Validate(value); err != nil {
+		result.Valid = false
+		var validationErr *jsonschema.ValidationError
+		if errors.As(err, &validationErr) {
+			result.Errors = schemaErrorMessages(validationErr)
+		} else {
+			result.Errors = []string{err.Error()}
+		}
+	}
+	return result
+}
+
+// 校验失败时的400响应，配置了ErrorFile时原样返回该文件
+func writeSchemaRejection(c *gin.Context, config *RequestSchemaConfig, result *SchemaValidationResult) {
+	if config.ErrorFile != "" {
+		if data, err := readProjectFile(currentProject, config.ErrorFile); err == nil {
+			c.Data(http.StatusBadRequest, assetMimeType(config.ErrorFile, data), data)
+			return
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "请求体不符合JSON Schema",
+		"schema":  result.Schema,
+		"details": result.Errors,
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 请求体类型
+const (
+	bodyTypeRaw       = "raw"
+	bodyTypeForm      = "form"
+	bodyTypeMultipart = "multipart"
+	bodyTypeBinary    = "binary"
+)
+
+// 表单字段，File非空时作为文件上传（仅multipart），文件取自项目文件目录
+type FormField struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	File        string `json:"file,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// 根据请求体类型构造请求体，返回请求体和对应的Content-Type（为空表示不覆盖）
+func buildRequestBody(project string, req SendRequest) (io.Reader, string, error) {
+	switch req.BodyType {
+	case "", bodyTypeRaw:
+		if req.Data == "" {
+			return nil, "", nil
+		}
+		return strings.NewReader(req.Data), "application/json", nil
+
+	case bodyTypeForm:
+		values := url.Values{}
+		for _, field := range req.FormFields {
+			if field.File != "" {
+				return nil, "", errors.New("URL编码表单不支持文件字段")
+			}
+			values.Add(field.Key, field.Value)
+		}
+		return strings.NewReader(values.Encode()), "application/x-www-form-urlencoded", nil
+
+	case bodyTypeMultipart:
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, field := range req.FormFields {
+			if err := writeMultipartField(project, writer, field); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, writer.FormDataContentType(), nil
+
+	case bodyTypeBinary:
+		path, err := resolveProjectFile(project, req.BodyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("读取文件失败: %v", err)
+		}
+		return bytes.NewReader(data), contentTypeByName(req.BodyFile), nil
+
+	default:
+		return nil, "", fmt.Errorf("不支持的请求体类型: %s", req.BodyType)
+	}
+}
+
+func writeMultipartField(project string, writer *multipart.Writer, field FormField) error {
+	if field.File == "" {
+		if field.ContentType == "" {
+			return writer.WriteField(field.Key, field.Value)
+		}
+		// 指定了Content-Type的普通字段，例如JSON元数据
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(field.Key)))
+		header.Set("Content-Type", field.ContentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write([]byte(field.Value))
+		return err
+	}
+
+	path, err := resolveProjectFile(project, field.File)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	contentType := field.ContentType
+	if contentType == "" {
+		contentType = contentTypeByName(field.File)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field.Key), escapeQuotes(field.File)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// 系统MIME表中可能缺失的常用类型
+var extraContentTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".txt":  "text/plain; charset=utf-8",
+	".zip":  "application/zip",
+	".flv":  "video/x-flv",
+	".m3u8": "application/vnd.apple.mpegurl",
+}
+
+// 根据文件扩展名推断Content-Type
+func contentTypeByName(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct
+	}
+	if ct, ok := extraContentTypes[ext]; ok {
+		return ct
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/gin-gonic/gin"
+)
+
+const endpointTypeSOAP = "soap"
+
+// SOAP版本，1.1使用text/xml，1.2使用application/soap+xml
+const (
+	soapVersion11 = "1.1"
+	soapVersion12 = "1.2"
+)
+
+const (
+	soap11EnvelopeNS = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12EnvelopeNS = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// SOAP接口配置，没有规则匹配时返回ResponseFile，未配置时返回Client错误
+type SOAPConfig struct {
+	Version      string     `json:"version,omitempty"`
+	ResponseFile string     `json:"response_file,omitempty"`
+	Rules        []SOAPRule `json:"rules,omitempty"`
+}
+
+// SOAP匹配规则，设置的条件全部满足才算命中，按顺序取第一条命中的规则。
+// Operation为Body下第一个元素的本地名；XPath中的元素不带命名空间前缀，结果为布尔值时直接判断，
+// 否则与Value比较，Value为空时只要求结果非空。命中后返回ResponseFile或Fault
+type SOAPRule struct {
+	SOAPAction   string     `json:"soap_action,omitempty"`
+	Operation    string     `json:"operation,omitempty"`
+	XPath        string     `json:"xpath,omitempty"`
+	Value        string     `json:"value,omitempty"`
+	ResponseFile string     `json:"response_file,omitempty"`
+	Fault        *SOAPFault `json:"fault,omitempty"`
+}
+
+// SOAP Fault模板，Code不带前缀时自动加上soap:，
+// 1.1的Client/Server与1.2的Sender/Receiver按版本互相转换；Detail为原样嵌入的XML
+type SOAPFault struct {
+	Code   string `json:"code,omitempty"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func isXMLFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".xml")
+}
+
+// 检查XML是否格式良好且只有一个根元素
+func validateXML(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return errors.New("根元素之外不能有文本")
+			}
+		}
+	}
+	if roots != 1 {
+		return fmt.Errorf("应有且只有一个根元素，实际为%d个", roots)
+	}
+	return nil
+}
+
+// 按SOAP信封的命名空间选择Content-Type
+func xmlContentType(data []byte) string {
+	if bytes.Contains(data, []byte(soap12EnvelopeNS)) {
+		return "application/soap+xml; charset=utf-8"
+	}
+	return "text/xml; charset=utf-8"
+}
+
+func (cfg *SOAPConfig) contentType() string {
+	if cfg.Version == soapVersion12 {
+		return "application/soap+xml; charset=utf-8"
+	}
+	return "text/xml; charset=utf-8"
+}
+
+// 1.1从SOAPAction请求头读取，1.2从Content-Type的action参数读取
+func requestSOAPAction(r *http.Request) string {
+	if action := r.Header.Get("SOAPAction"); action != "" {
+		return strings.Trim(action, `"`)
+	}
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		return strings.Trim(params["action"], `"`)
+	}
+	return ""
+}
+
+func (f SOAPFault) render(version string) []byte {
+	code := f.Code
+	if code == "" {
+		code = "Server"
+	}
+	code = strings.TrimPrefix(code, "soap:")
+	if version == soapVersion12 {
+		switch code {
+		case "Client":
+			code = "Sender"
+		case "Server":
+			code = "Receiver"
+		}
+	} else {
+		switch code {
+		case "Sender":
+			code = "Client"
+		case "Receiver":
+			code = "Server"
+		}
+	}
+
+	var reason bytes.Buffer
+	xml.EscapeText(&reason, []byte(f.Reason))
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	if version == soapVersion12 {
+		buf.WriteString(`<soap:Envelope xmlns:soap="` + soap12EnvelopeNS + `">` + "\n")
+		buf.WriteString("  <soap:Body>\n    <soap:Fault>\n")
+		buf.WriteString("      <soap:Code><soap:Value>soap:" + code + "</soap:Value></soap:Code>\n")
+		buf.WriteString(`      <soap:Reason><soap:Text xml:lang="zh">` + reason.String() + "</soap:Text></soap:Reason>\n")
+		if f.Detail != "" {
+			buf.WriteString("      <soap:Detail>" + f.Detail + "</soap:Detail>\n")
+		}
+	} else {
+		buf.WriteString(`<soap:Envelope xmlns:soap="` + soap11EnvelopeNS + `">` + "\n")
+		buf.WriteString("  <soap:Body>\n    <soap:Fault>\n")
+		buf.WriteString("      <faultcode>soap:" + code + "</faultcode>\n")
+		buf.WriteString("      <faultstring>" + reason.String() + "</faultstring>\n")
+		if f.Detail != "" {
+			buf.WriteString("      <detail>" + f.Detail + "</detail>\n")
+		}
+	}
+	buf.WriteString("    </soap:Fault>\n  </soap:Body>\n</soap:Envelope>\n")
+	return buf.Bytes()
+}
+
+// 计算XPath表达式，节点集取第一个节点的文本
+func evaluateXPath(doc *xmlquery.Node, expr *xpath.Expr) (string, bool) {
+	switch result := expr.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+	case bool:
+		return strconv.FormatBool(result), result
+	case float64:
+		return strconv.FormatFloat(result, 'f', -1, 64), true
+	case string:
+		return result, result != ""
+	case *xpath.NodeIterator:
+		if result.MoveNext() {
+			return strings.TrimSpace(result.Current().Value()), true
+		}
+	}
+	return "", false
+}
+
+func (r SOAPRule) matches(doc *xmlquery.Node, action, operation string) (bool, error) {
+	if r.SOAPAction != "" && r.SOAPAction != action {
+		return false, nil
+	}
+	if r.Operation != "" && r.Operation != operation {
+		return false, nil
+	}
+	if r.XPath == "" {
+		return true, nil
+	}
+	expr, err := xpath.Compile(r.XPath)
+	if err != nil {
+		return false, fmt.Errorf("XPath表达式错误 %q: %v", r.XPath, err)
+	}
+	value, ok := evaluateXPath(doc, expr)
+	if r.Value == "" {
+		return ok, nil
+	}
+	return ok && value == r.Value, nil
+}
+
+// 去掉元素的命名空间前缀，规则中的XPath可以直接写本地名，如//Body/GetVideo
+func stripXMLPrefixes(node *xmlquery.Node) {
+	for n := node; n != nil; n = n.NextSibling {
+		if n.Type == xmlquery.ElementNode {
+			n.Prefix = ""
+		}
+		stripXMLPrefixes(n.FirstChild)
+	}
+}
+
+// Body下第一个元素的本地名，即调用的操作
+func soapOperation(doc *xmlquery.Node) string {
+	body := xmlquery.FindOne(doc, "/Envelope/Body/*[1]")
+	if body == nil {
+		return ""
+	}
+	return body.Data
+}
+
+// SOAP接口：按SOAPAction、操作名和XPath匹配规则，返回响应文件或Fault
+func handleSOAPEndpoint(c *gin.Context, path string, config *SOAPConfig) {
+	requestLog := recordIncomingRequest(c, path)
+	if config == nil {
+		config = &SOAPConfig{}
+	}
+	version := config.Version
+
+	writeFault := func(fault SOAPFault) {
+		c.Data(http.StatusInternalServerError, config.contentType(), fault.render(version))
+	}
+
+	if c.Request.Method != http.MethodPost {
+		writeFault(SOAPFault{Code: "Client", Reason: "SOAP请求必须使用POST"})
+		return
+	}
+	body := []byte(requestLog.Body)
+	if err := validateXML(body); err != nil {
+		writeFault(SOAPFault{Code: "Client", Reason: "请求不是有效的XML: " + err.Error()})
+		return
+	}
+	doc, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		writeFault(SOAPFault{Code: "Client", Reason: "请求不是有效的XML: " + err.Error()})
+		return
+	}
+
+	stripXMLPrefixes(doc)
+	action := requestSOAPAction(c.Request)
+	operation := soapOperation(doc)
+	responseFile := config.ResponseFile
+	for _, rule := range config.Rules {
+		ok, err := rule.matches(doc, action, operation)
+		if err != nil {
+			writeFault(SOAPFault{Code: "Server", Reason: err.Error()})
+			return
+		}
+		if !ok {
+			continue
+		}
+		if rule.Fault != nil {
+			writeFault(*rule.Fault)
+			return
+		}
+		responseFile = rule.ResponseFile
+		break
+	}
+
+	if responseFile == "" {
+		writeFault(SOAPFault{Code: "Client", Reason: fmt.Sprintf("没有匹配的规则: SOAPAction=%s, 操作=%s", action, operation)})
+		return
+	}
+	data, err := readProjectFile(currentProject, responseFile)
+	if err != nil {
+		writeFault(SOAPFault{Code: "Server", Reason: "读取响应文件失败: " + err.Error()})
+		return
+	}
+
+	// 响应文件本身是Fault时按规范返回500
+	status := http.StatusOK
+	if responseDoc, err := xmlquery.Parse(bytes.NewReader(data)); err == nil && xmlquery.FindOne(responseDoc, "//*[local-name()='Fault']") != nil {
+		status = http.StatusInternalServerError
+	}
+	c.Data(status, config.contentType(), data)
+}
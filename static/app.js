@@ -13,6 +13,12 @@ class HTTPJSONTool {
         this.loadStatus();
         this.initTabs();
         this.loadSendBlocks();
+        this.loadSendHistory();
+        this.loadRevisions();
+        this.loadWSMockConnections();
+        this.loadStreamClients();
+        this.loadProtos();
+        this.loadGraphQLSchemas();
     }
 
     connectWebSocket() {
@@ -33,6 +39,14 @@ class HTTPJSONTool {
                 this.addRequestLog(message.data);
             } else if (message.type === 'server_error') {
                 this.handleServerError(message.error);
+            } else if (message.type === 'loadtest_progress' || message.type === 'loadtest_done') {
+                this.displayLoadTest(message.data);
+            } else if (message.type === 'ws_mock_connection') {
+                this.loadWSMockConnections();
+            } else if (message.type === 'stream_client_frame') {
+                this.addStreamFrame(message.data);
+            } else if (message.type === 'stream_client_status') {
+                this.handleStreamStatus(message.data);
             }
         };
 
@@ -51,8 +65,39 @@ class HTTPJSONTool {
         document.getElementById('clear-logs').addEventListener('click', () => this.clearLogs());
         document.getElementById('refresh-logs').addEventListener('click', () => this.refreshLogs());
 
+        // 流式连接
+        document.getElementById('stream-connect').addEventListener('click', () => this.connectStreamClient());
+        document.getElementById('stream-send').addEventListener('click', () => this.sendStreamMessage());
+        document.getElementById('stream-close').addEventListener('click', () => this.closeStreamClient());
+
+        // gRPC
+        document.getElementById('grpc-proto-upload').addEventListener('change', (e) => this.uploadProtos(e.target));
+        document.getElementById('graphql-schema-upload').addEventListener('change', (e) => this.uploadGraphQLSchema(e.target));
+
+        // TCP/UDP监听
+        document.getElementById('listeners-config').addEventListener('change', (e) => {
+            const text = e.target.value.trim();
+            try {
+                this.listeners = text ? JSON.parse(text) : [];
+            } catch (error) {
+                this.showMessage('监听配置格式错误: ' + error.message, 'error');
+            }
+        });
+
+        // WebSocket推送
+        document.getElementById('ws-mock-push').addEventListener('click', () => this.pushWSMockMessage());
+
+        // 发送记录
+        document.getElementById('refresh-history').addEventListener('click', () => this.loadSendHistory());
+        document.getElementById('diff-history').addEventListener('click', () => this.diffSendHistory());
+        document.getElementById('refresh-revisions').addEventListener('click', () => this.loadRevisions());
+
         // JSON编辑器
         document.getElementById('edit-json').addEventListener('click', () => this.enableJSONEdit());
+        document.getElementById('create-folder').addEventListener('click', () => this.createFolder());
+        document.getElementById('upload-files').addEventListener('click', () => this.uploadFiles(false));
+        document.getElementById('generate-fake').addEventListener('click', () => this.generateFakeFixture());
+        document.getElementById('reset-resources').addEventListener('click', () => this.resetResources());
         document.getElementById('save-json').addEventListener('click', () => this.saveJSONFile());
         document.getElementById('close-json-editor').addEventListener('click', () => this.closeJSONEditor());
     }
@@ -71,11 +116,178 @@ class HTTPJSONTool {
         try {
             const response = await fetch('/api/files');
             const files = await response.json();
-            this.jsonFiles = files || [];
+            // 下拉框只需要文件名，文件管理使用完整的元数据
+            this.fileInfos = files || [];
+            this.jsonFiles = this.fileInfos.filter(file => !file.is_dir).map(file => file.name);
         } catch (error) {
             console.error('加载JSON文件列表失败:', error);
+            this.fileInfos = [];
             this.jsonFiles = [];
         }
+        this.renderProjectFiles();
+    }
+
+    renderProjectFiles() {
+        const container = document.getElementById('project-files');
+        if (!container) return;
+        container.innerHTML = '';
+        if (this.fileInfos.length === 0) {
+            container.innerHTML = '<p>暂无文件</p>';
+            return;
+        }
+
+        const formatSize = size => size < 1024 ? size + 'B' : size < 1048576 ? (size / 1024).toFixed(1) + 'KB' : (size / 1048576).toFixed(1) + 'MB';
+        this.fileInfos.forEach(file => {
+            const item = document.createElement('div');
+            item.className = 'log-item';
+            const depth = file.name.split('/').length - 1;
+            const name = file.name.split('/').pop();
+            const editable = ['json', 'xml', 'text'].includes(file.type);
+            const encoded = encodeURIComponent(file.name).replace(/'/g, '%27');
+            item.innerHTML = `
+                <div class="log-header" style="padding-left: ${depth * 16}px;">
+                    <span>${file.is_dir ? '📁' : '📄'} ${name}</span>
+                    <span style="font-size: 11px; color: #666;">${file.type}${file.is_dir ? '' : ' / ' + formatSize(file.size)}</span>
+                    <span style="font-size: 11px; color: #666;">${new Date(file.modified).toLocaleString('zh-CN')}</span>
+                    ${editable ? `<button class="toggle-btn" onclick="tool.editJSONFile(decodeURIComponent('${encoded}'))">编辑</button>` : ''}
+                    ${file.is_dir ? '' : `<a class="toggle-btn" href="/api/files/download?file=${encoded}">下载</a>`}
+                    <button class="toggle-btn" onclick="tool.fileAction('rename', decodeURIComponent('${encoded}'))">重命名</button>
+                    ${file.is_dir ? '' : `<button class="toggle-btn" onclick="tool.fileAction('duplicate', decodeURIComponent('${encoded}'))">复制</button>`}
+                    <button class="toggle-btn" onclick="tool.fileAction('delete', decodeURIComponent('${encoded}'))">删除</button>
+                </div>
+            `;
+            container.appendChild(item);
+        });
+    }
+
+    // 重命名、复制、删除文件
+    async fileAction(action, name) {
+        let response;
+        try {
+            if (action === 'delete') {
+                if (!confirm('确定删除 ' + name + '？文件内容会保留在版本历史中。')) return;
+                response = await fetch('/api/files?file=' + encodeURIComponent(name), { method: 'DELETE' });
+            } else {
+                const to = prompt(action === 'rename' ? '新的路径:' : '复制到（留空自动命名）:', action === 'rename' ? name : '');
+                if (to === null || (action === 'rename' && (to === '' || to === name))) return;
+                response = await fetch('/api/files/' + action, {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ from: name, to: to })
+                });
+            }
+            const result = await response.json();
+            if (!response.ok) {
+                this.showMessage('操作失败: ' + result.error, 'error');
+                return;
+            }
+            this.showMessage(result.message, 'success');
+            await this.refreshProjectFiles();
+        } catch (error) {
+            this.showMessage('操作失败: ' + error.message, 'error');
+        }
+    }
+
+    async createFolder() {
+        const path = document.getElementById('file-dir').value.trim().replace(/^\/+|\/+$/g, '');
+        if (!path) {
+            this.showMessage('请输入目录名', 'error');
+            return;
+        }
+        try {
+            const response = await fetch('/api/files/mkdir', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ path: path })
+            });
+            const result = await response.json();
+            this.showMessage(response.ok ? result.message : '创建目录失败: ' + result.error, response.ok ? 'success' : 'error');
+            await this.refreshProjectFiles();
+        } catch (error) {
+            this.showMessage('创建目录失败: ' + error.message, 'error');
+        }
+    }
+
+    // 按JSON Schema或示例文件生成测试数据并保存为项目文件
+    async generateFakeFixture() {
+        const source = prompt('JSON Schema文件（.schema.json结尾），或作为示例推断结构的JSON文件:');
+        if (!source) return;
+        const count = parseInt(prompt('生成条数，0为生成单个对象:', '10') || '0', 10);
+        const seed = parseInt(prompt('随机种子，相同种子生成相同数据，留空随机:', '') || '0', 10);
+        const saveAs = prompt('保存为:', source.replace(/(\.schema)?\.json$/, '') + '_fake.json');
+        if (!saveAs) return;
+
+        const request = { count: count || 0, seed: seed || 0, save_as: saveAs };
+        request[/\.schema\.json$/.test(source) ? 'schema_file' : 'example_file'] = source;
+        try {
+            const response = await fetch('/api/fake-data', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(request)
+            });
+            const result = await response.json();
+            if (response.ok) {
+                this.showMessage(`已生成 ${saveAs}，种子 ${result.seed}`, 'success');
+                await this.refreshProjectFiles();
+            } else {
+                this.showMessage('生成数据失败: ' + result.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('生成数据失败: ' + error.message, 'error');
+        }
+    }
+
+    // 把当前项目所有CRUD资源恢复为种子数据
+    async resetResources() {
+        if (!confirm('确定把所有CRUD资源恢复为种子数据吗？新增和修改的记录将丢失')) return;
+        try {
+            const response = await fetch('/api/resources/reset', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: '{}'
+            });
+            const result = await response.json();
+            this.showMessage(response.ok ? result.message : '重置失败: ' + result.error, response.ok ? 'success' : 'error');
+        } catch (error) {
+            this.showMessage('重置失败: ' + error.message, 'error');
+        }
+    }
+
+    async uploadFiles(overwrite) {
+        const input = document.getElementById('file-upload');
+        if (input.files.length === 0) {
+            this.showMessage('请选择要上传的文件', 'error');
+            return;
+        }
+        const form = new FormData();
+        Array.from(input.files).forEach(file => form.append('files', file));
+        form.append('dir', document.getElementById('file-dir').value.trim());
+        if (overwrite) form.append('overwrite', 'true');
+
+        try {
+            const response = await fetch('/api/files/upload', { method: 'POST', body: form });
+            const result = await response.json();
+            if (response.status === 409 && !overwrite) {
+                if (confirm(result.error + '，是否覆盖？')) await this.uploadFiles(true);
+                return;
+            }
+            if (!response.ok) {
+                this.showMessage('上传失败: ' + result.error, 'error');
+                return;
+            }
+            input.value = '';
+            this.showMessage(result.message, 'success');
+            await this.refreshProjectFiles();
+        } catch (error) {
+            this.showMessage('上传失败: ' + error.message, 'error');
+        }
+    }
+
+    // 文件变化后刷新列表和各处的文件下拉框
+    async refreshProjectFiles() {
+        await this.loadJSONFiles();
+        this.updateEndpointsUI();
+        this.renderSendBlocks();
     }
 
     updateUI(data) {
@@ -91,7 +303,8 @@ class HTTPJSONTool {
         if (data.is_running) {
             statusElement.textContent = '运行中';
             statusElement.className = 'status-running';
-            urlElement.textContent = `http://${data.ip}:${data.port}`;
+            const scheme = data.tls && data.tls.enabled ? 'https' : 'http';
+            urlElement.textContent = `${scheme}://${data.ip}:${data.port}`;
             startBtn.disabled = true;
             stopBtn.disabled = false;
         } else {
@@ -102,6 +315,26 @@ class HTTPJSONTool {
             stopBtn.disabled = true;
         }
 
+        // 项目默认的发送传输配置，页面上不编辑，保存时原样带回
+        this.sendTransport = data.send_transport || null;
+
+        // 证书文件等其它TLS配置页面上不编辑，保存时原样带回
+        this.tlsConfig = data.tls || null;
+        document.getElementById('server-tls').checked = !!(this.tlsConfig && this.tlsConfig.enabled);
+        document.getElementById('server-client-auth').value = (this.tlsConfig && this.tlsConfig.client_auth) || '';
+        document.getElementById('server-h2c').checked = !!data.h2c;
+
+        // gRPC配置
+        this.grpcConfig = data.grpc || null;
+        document.getElementById('grpc-enabled').checked = !!(this.grpcConfig && this.grpcConfig.enabled);
+        document.getElementById('grpc-port').value = (this.grpcConfig && this.grpcConfig.port) || '';
+        const methods = (this.grpcConfig && this.grpcConfig.methods) || [];
+        document.getElementById('grpc-methods').value = methods.length > 0 ? JSON.stringify(methods, null, 2) : '';
+
+        // TCP/UDP监听配置
+        this.listeners = data.listeners || [];
+        document.getElementById('listeners-config').value = this.listeners.length > 0 ? JSON.stringify(this.listeners, null, 2) : '';
+
         // 更新接口配置
         this.endpoints = data.endpoints || [];
         this.updateEndpointsUI();
@@ -160,12 +393,69 @@ class HTTPJSONTool {
                         </select>
                     </div>
                 </div>
+                <div style="display: flex; gap: 8px; margin-top: 3px;">
+                    <select onchange="tool.updateEndpointType(${index}, this.value)"
+                            style="padding: 4px; font-size: 13px;">
+                        <option value="" ${!endpoint.type ? 'selected' : ''}>HTTP</option>
+                        <option value="websocket" ${endpoint.type === 'websocket' ? 'selected' : ''}>WebSocket</option>
+                        <option value="stream" ${endpoint.type === 'stream' ? 'selected' : ''}>流式响应</option>
+                        <option value="graphql" ${endpoint.type === 'graphql' ? 'selected' : ''}>GraphQL</option>
+                        <option value="soap" ${endpoint.type === 'soap' ? 'selected' : ''}>SOAP</option>
+                        <option value="file" ${endpoint.type === 'file' ? 'selected' : ''}>文件下载</option>
+                        <option value="fake" ${endpoint.type === 'fake' ? 'selected' : ''}>随机数据</option>
+                        <option value="resource" ${endpoint.type === 'resource' ? 'selected' : ''}>CRUD资源</option>
+                    </select>
+                </div>
+                ${this.endpointScriptFields[endpoint.type || ''] ? `<textarea class="endpoint-script" rows="5" placeholder='${this.endpointScriptPlaceholders[endpoint.type || '']}' onchange="tool.updateEndpointScript(${index}, this.value)" style="width: 100%; margin-top: 3px; font-family: monospace; font-size: 12px;"></textarea>` : ''}
             `;
 
+            // 脚本内容通过value设置，避免被当作HTML解析
+            const field = this.endpointScriptFields[endpoint.type || ''];
+            const script = endpointDiv.querySelector('.endpoint-script');
+            if (script && endpoint[field]) {
+                script.value = JSON.stringify(endpoint[field], null, 2);
+            }
+
             container.appendChild(endpointDiv);
         });
     }
 
+    updateEndpointType(index, type) {
+        this.endpoints[index].type = type;
+        this.updateEndpointsUI();
+    }
+
+    // 各接口类型对应的配置字段，HTTP接口配置请求体Schema校验
+    get endpointScriptFields() {
+        return { '': 'request_schema', websocket: 'websocket', stream: 'stream', graphql: 'graphql', soap: 'soap', file: 'file', fake: 'fake', resource: 'resource' };
+    }
+
+    get endpointScriptPlaceholders() {
+        return {
+            '': '{"file": "schemas/request.schema.json", "reject": true, "error_file": "bad_request.json"}  请求体JSON Schema校验，留空不校验',
+            websocket: '{"on_connect": [], "rules": [], "periodic": []}',
+            stream: '{"format": "sse", "mode": "finite", "interval_ms": 1000, "events": [{"event": "progress", "data": "{seq}"}]}',
+            graphql: '{"schema_file": "schema.graphql", "fixtures": {"user": "user.json"}, "rules": [{"operation_name": "GetUser", "variables": {"id": "1"}, "response_file": "user1.json"}]}',
+            soap: '{"version": "1.1", "response_file": "default.xml", "rules": [{"soap_action": "urn:GetVideo", "xpath": "//VideoId", "value": "404", "fault": {"code": "Client", "reason": "视频不存在"}}]}',
+            file: '{"path": "videos/", "rate_kbps": 512, "attachment": false}  路径以/结尾时为目录，接口路径写作/download/*filepath；不填path时使用响应文件',
+            fake: '{"schema_file": "schemas/user.schema.json", "count": 10, "seed": 0}  不填schema_file时按响应文件推断结构；seed为0时每次随机，请求可带?_seed=复现',
+            resource: '{"seed_file": "user_list.json", "items_field": "users", "id_field": "id", "persist": false}  接口路径为基础路径如/api/users，同时提供/api/users/:id；不填seed_file时使用响应文件'
+        };
+    }
+
+    updateEndpointScript(index, text) {
+        const field = this.endpointScriptFields[this.endpoints[index].type || ''];
+        if (!text.trim()) {
+            this.endpoints[index][field] = null;
+            return;
+        }
+        try {
+            this.endpoints[index][field] = JSON.parse(text);
+        } catch (e) {
+            this.showMessage('接口配置格式错误: ' + e.message, 'error');
+        }
+    }
+
     updateEndpointName(index, name) {
         this.endpoints[index].name = name;
     }
@@ -255,6 +545,107 @@ class HTTPJSONTool {
         }
     }
 
+    readGRPCForm() {
+        const enabled = document.getElementById('grpc-enabled').checked;
+        const port = document.getElementById('grpc-port').value.trim();
+        const methodsText = document.getElementById('grpc-methods').value.trim();
+        if (!enabled && !port && !methodsText) {
+            return null;
+        }
+        let methods = (this.grpcConfig && this.grpcConfig.methods) || [];
+        try {
+            methods = methodsText ? JSON.parse(methodsText) : [];
+        } catch (e) {
+            this.showMessage('gRPC方法配置格式错误，保留原配置: ' + e.message, 'error');
+        }
+        return { enabled: enabled, port: port, methods: methods };
+    }
+
+    async loadProtos() {
+        try {
+            const response = await fetch('/api/grpc/protos');
+            const result = await response.json();
+            const container = document.getElementById('grpc-services');
+            if (result.compile_error) {
+                container.textContent = 'proto编译失败: ' + result.compile_error;
+                return;
+            }
+            const lines = [];
+            result.services.forEach(svc => svc.methods.forEach(m => {
+                lines.push(m.full_method + (m.client_streaming ? ' [客户端流]' : '') + (m.server_streaming ? ' [服务端流]' : ''));
+            }));
+            container.textContent = lines.length > 0 ? lines.join('\n') : '尚未上传proto文件';
+        } catch (error) {
+            console.error('加载proto失败:', error);
+        }
+    }
+
+    async uploadProtos(input) {
+        if (input.files.length === 0) {
+            return;
+        }
+        const form = new FormData();
+        for (const file of input.files) {
+            form.append('file', file);
+        }
+        try {
+            const response = await fetch('/api/grpc/protos', { method: 'POST', body: form });
+            const result = await response.json();
+            if (response.ok) {
+                this.showMessage('proto上传成功', 'success');
+                this.loadProtos();
+            } else {
+                this.showMessage(result.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('上传失败: ' + error.message, 'error');
+        }
+        input.value = '';
+    }
+
+    async loadGraphQLSchemas() {
+        try {
+            const response = await fetch('/api/graphql/schemas');
+            const schemas = await response.json();
+            const lines = schemas.map(s => s.parse_error
+                ? s.file + ': 解析失败 ' + s.parse_error
+                : s.file + '  query: ' + s.queries.join(', ') + (s.mutations.length > 0 ? '  mutation: ' + s.mutations.join(', ') : ''));
+            document.getElementById('graphql-schemas').textContent = lines.length > 0 ? lines.join('\n') : '尚未上传schema文件';
+        } catch (error) {
+            console.error('加载GraphQL schema失败:', error);
+        }
+    }
+
+    async uploadGraphQLSchema(input) {
+        if (input.files.length === 0) {
+            return;
+        }
+        const form = new FormData();
+        form.append('file', input.files[0]);
+        try {
+            const response = await fetch('/api/graphql/schemas', { method: 'POST', body: form });
+            const result = await response.json();
+            if (response.ok) {
+                this.showMessage('schema上传成功', 'success');
+                this.loadGraphQLSchemas();
+            } else {
+                this.showMessage(result.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('上传失败: ' + error.message, 'error');
+        }
+        input.value = '';
+    }
+
+    readTLSForm() {
+        const enabled = document.getElementById('server-tls').checked;
+        const clientAuth = document.getElementById('server-client-auth').value;
+        if (!enabled && !clientAuth && !this.tlsConfig) {
+            return null;
+        }
+        return { ...(this.tlsConfig || {}), enabled: enabled, client_auth: clientAuth };
+    }
+
     async saveConfig() {
         // 先更新发送块配置
         await this.saveSendBlocksConfig();
@@ -263,7 +654,12 @@ class HTTPJSONTool {
             ip: document.getElementById('server-ip').value,
             port: document.getElementById('server-port').value,
             endpoints: this.endpoints,
-            send_blocks: this.sendBlocks
+            send_blocks: this.sendBlocks,
+            send_transport: this.sendTransport,
+            tls: this.readTLSForm(),
+            h2c: document.getElementById('server-h2c').checked,
+            listeners: this.listeners,
+            grpc: this.readGRPCForm()
         };
 
         try {
@@ -335,26 +731,62 @@ class HTTPJSONTool {
 
     displayResponse(response) {
         const resultElement = document.getElementById('response-result');
+        resultElement.textContent = this.formatSendResult(response);
+    }
+
+    // 格式化发送结果：状态、耗时、重定向、TLS、响应头、响应体和原始请求
+    formatSendResult(result) {
+        let displayText = `状态码: ${result.status}  ${result.proto || ''}
+`;
+
+        const t = result.timings;
+        if (t) {
+            displayText += `耗时: DNS ${t.dns_ms}ms  连接 ${t.connect_ms}ms  TLS ${t.tls_ms}ms  首字节 ${t.ttfb_ms}ms  总计 ${t.total_ms}ms${t.conn_reused ? '  (复用连接)' : ''}
+`;
+        }
 
-        let displayText = `状态码: ${response.status}
+        if (result.redirects && result.redirects.length > 0) {
+            displayText += "\n重定向:\n";
+            result.redirects.forEach(hop => {
+                displayText += `  ${hop.status} ${hop.url} -> ${hop.location}
+`;
+            });
+            displayText += `  最终地址: ${result.final_url}
+`;
+        }
 
+        if (result.tls) {
+            displayText += `
+TLS: ${result.tls.version}  ${result.tls.cipher_suite}  ALPN: ${result.tls.negotiated_protocol || '-'}
 `;
-        displayText += "响应头:\n";
-        for (const [key, value] of Object.entries(response.headers)) {
-            displayText += `${key}: ${value}
+            (result.tls.peer_certificates || []).forEach(cert => {
+                displayText += `  证书: ${cert.subject}  签发者: ${cert.issuer}  有效期至: ${cert.not_after}
+`;
+            });
+        }
+
+        displayText += "\n响应头:\n";
+        for (const [key, value] of Object.entries(result.headers || {})) {
+            const values = Array.isArray(value) ? value : [value];
+            values.forEach(v => {
+                displayText += `${key}: ${v}
 `;
+            });
         }
         displayText += "\n响应体:\n";
 
         // 尝试格式化JSON
         try {
-            const jsonBody = JSON.parse(response.body);
+            const jsonBody = JSON.parse(result.body);
             displayText += JSON.stringify(jsonBody, null, 2);
         } catch {
-            displayText += response.body;
+            displayText += result.body;
         }
 
-        resultElement.textContent = displayText;
+        if (result.raw_request) {
+            displayText += "\n\n实际发送的请求:\n" + result.raw_request;
+        }
+        return displayText;
     }
 
     formatJSON() {
@@ -444,6 +876,19 @@ class HTTPJSONTool {
                         <button onclick="tool.sendBlockRequest(${index})" class="btn btn-primary" style="padding: 8px 20px;">发送</button>
                     </div>
                 </div>
+                <div class="form-row" style="display: flex; gap: 10px; margin-bottom: 10px; align-items: center;">
+                    <label style="margin: 0;">请求体类型:</label>
+                    <select id="send-body-type-${index}" style="padding: 6px;">
+                        <option value="raw" ${!block.body_type || block.body_type === 'raw' ? 'selected' : ''}>JSON/原始文本</option>
+                        <option value="form" ${block.body_type === 'form' ? 'selected' : ''}>x-www-form-urlencoded</option>
+                        <option value="multipart" ${block.body_type === 'multipart' ? 'selected' : ''}>multipart/form-data</option>
+                        <option value="binary" ${block.body_type === 'binary' ? 'selected' : ''}>二进制文件</option>
+                    </select>
+                    <input type="text" id="send-body-file-${index}" value="${block.body_file || ''}" placeholder="二进制文件名（项目文件）" style="flex: 0 0 240px; padding: 6px;">
+                    <input type="text" id="send-transport-${index}" value='${block.transport ? JSON.stringify(block.transport) : ''}' placeholder='传输配置，如 {"insecure_skip_verify":true}' style="flex: 0 0 300px; padding: 6px;">
+                    <input type="text" id="send-auth-${index}" value='${block.auth ? JSON.stringify(block.auth) : ''}' placeholder='认证配置，如 {"type":"bearer","token":"..."}' style="flex: 0 0 300px; padding: 6px;">
+                    <span style="font-size: 12px; color: #666;">表单类型时请求数据填写JSON对象，值以@开头表示上传项目文件，如 {"meta": {...}, "video": "@test.mp4"}</span>
+                </div>
                 <div class="form-row" style="display: flex; gap: 10px; margin-bottom: 10px;">
                     <div class="form-group" style="flex: 1;">
                         <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 5px;">
@@ -454,13 +899,47 @@ class HTTPJSONTool {
                                 <button id="save-btn-${index}" onclick="tool.saveSendBlockData(${index})" class="btn btn-success" style="padding: 3px 10px; display: none;">保存</button>
                             </div>
                         </div>
-                        <textarea id="send-data-${index}" rows="6" placeholder='{"key": "value"}' style="width: 100%; background-color: #f5f5f5;" readonly>${block.data || ''}</textarea>
+                        <textarea id="send-data-${index}" rows="6" placeholder='{"key": "value"}' style="width: 100%; background-color: #f5f5f5;" readonly>${block.data || this.formFieldsToText(block.form_fields)}</textarea>
                     </div>
                 </div>
                 <div class="response-section">
                     <h4>响应结果:</h4>
                     <pre id="send-response-${index}" style="background: #f5f5f5; padding: 10px; border-radius: 4px; max-height: 300px; overflow: auto;"></pre>
                 </div>
+                <details class="loadtest-section" style="margin-top: 10px;">
+                    <summary style="cursor: pointer; font-weight: bold;">压测</summary>
+                    <div class="form-row" style="display: flex; gap: 10px; margin: 10px 0; align-items: flex-end; flex-wrap: wrap;">
+                        <div class="form-group" style="flex: 0 0 90px;">
+                            <label>并发数</label>
+                            <input type="number" id="lt-concurrency-${index}" value="10" min="1" style="width: 100%; padding: 6px;">
+                        </div>
+                        <div class="form-group" style="flex: 0 0 90px;">
+                            <label>请求总数</label>
+                            <input type="number" id="lt-total-${index}" value="0" min="0" style="width: 100%; padding: 6px;">
+                        </div>
+                        <div class="form-group" style="flex: 0 0 90px;">
+                            <label>持续秒数</label>
+                            <input type="number" id="lt-duration-${index}" value="60" min="0" style="width: 100%; padding: 6px;">
+                        </div>
+                        <div class="form-group" style="flex: 0 0 90px;">
+                            <label>起始RPS</label>
+                            <input type="number" id="lt-start-rps-${index}" value="10" min="0" style="width: 100%; padding: 6px;">
+                        </div>
+                        <div class="form-group" style="flex: 0 0 90px;">
+                            <label>目标RPS</label>
+                            <input type="number" id="lt-target-rps-${index}" value="200" min="0" style="width: 100%; padding: 6px;">
+                        </div>
+                        <div class="form-group" style="flex: 0 0 90px;">
+                            <label>爬坡秒数</label>
+                            <input type="number" id="lt-ramp-${index}" value="10" min="0" style="width: 100%; padding: 6px;">
+                        </div>
+                        <div class="form-actions" style="display: flex; gap: 5px;">
+                            <button onclick="tool.startLoadTest(${index})" class="btn btn-primary" style="padding: 6px 15px;">开始压测</button>
+                            <button onclick="tool.stopLoadTest()" class="btn btn-secondary" style="padding: 6px 15px;">停止</button>
+                        </div>
+                    </div>
+                    <pre id="lt-result-${index}" style="background: #f5f5f5; padding: 10px; border-radius: 4px; max-height: 300px; overflow: auto;"></pre>
+                </details>
             </div>
         `;
 
@@ -520,13 +999,46 @@ class HTTPJSONTool {
             }
         }
 
+        const bodyType = document.getElementById(`send-body-type-${index}`).value;
         const request = {
             url: url,
             method: method,
             headers: headers,
-            data: data
+            data: data,
+            body_type: bodyType,
+            body_file: document.getElementById(`send-body-file-${index}`).value
         };
 
+        const authText = document.getElementById(`send-auth-${index}`).value;
+        if (authText.trim()) {
+            try {
+                request.auth = JSON.parse(authText);
+            } catch (error) {
+                alert('认证配置格式错误: ' + error.message);
+                return;
+            }
+        }
+
+        const transportText = document.getElementById(`send-transport-${index}`).value;
+        if (transportText.trim()) {
+            try {
+                request.transport = JSON.parse(transportText);
+            } catch (error) {
+                alert('传输配置格式错误: ' + error.message);
+                return;
+            }
+        }
+
+        if (bodyType === 'form' || bodyType === 'multipart') {
+            try {
+                request.form_fields = this.buildFormFields(data);
+                request.data = '';
+            } catch (error) {
+                alert('表单字段格式错误: ' + error.message);
+                return;
+            }
+        }
+
         try {
             const response = await fetch('/api/send', {
                 method: 'POST',
@@ -537,31 +1049,343 @@ class HTTPJSONTool {
 
             const responseElement = document.getElementById(`send-response-${index}`);
             if (response.ok) {
-                let displayText = `状态码: ${result.status}
+                responseElement.textContent = this.formatSendResult(result);
+            } else {
+                responseElement.textContent = '发送请求失败: ' + result.error;
+            }
+        } catch (error) {
+            document.getElementById(`send-response-${index}`).textContent = '发送请求失败: ' + error.message;
+        }
+
+        // 保存当前配置
+        this.updateSendBlockConfig(index);
+        this.loadSendHistory();
+    }
+
+    // 加载发送记录
+    async loadSendHistory() {
+        const keyword = document.getElementById('history-search').value;
+        try {
+            const response = await fetch(`/api/send-history?limit=50&q=${encodeURIComponent(keyword)}`);
+            const result = await response.json();
+            const container = document.getElementById('send-history');
+            container.innerHTML = '';
+
+            if (!result.entries || result.entries.length === 0) {
+                container.innerHTML = '<p>暂无发送记录</p>';
+                return;
+            }
+
+            result.entries.forEach(entry => {
+                const item = document.createElement('div');
+                item.className = 'log-item';
+                const time = new Date(entry.timestamp).toLocaleString('zh-CN');
+                const status = entry.error ? '失败' : entry.status;
+                item.innerHTML = `
+                    <div class="log-header">
+                        <span>#${entry.id}</span>
+                        <span class="log-method ${entry.method}">${entry.method}</span>
+                        <span>${entry.url}</span>
+                        <span style="font-size: 11px; color: #666;">${time}</span>
+                        <span style="font-size: 11px; color: #666;">${status} / ${entry.total_ms}ms / ${entry.source}</span>
+                        <button class="toggle-btn" onclick="tool.showSendHistoryEntry(${entry.id})">查看</button>
+                        <button class="toggle-btn" onclick="tool.resendHistoryEntry(${entry.id})">重发</button>
+                    </div>
+                `;
+                container.appendChild(item);
+            });
+        } catch (error) {
+            console.error('加载发送记录失败:', error);
+        }
+    }
+
+    async showSendHistoryEntry(id) {
+        try {
+            const response = await fetch(`/api/send-history/${id}`);
+            const entry = await response.json();
+            const detail = document.getElementById('send-history-detail');
+            let text = `#${entry.id}  ${entry.request.method} ${entry.request.url}
+`;
+            text += entry.result ? this.formatSendResult(entry.result) : '发送失败: ' + entry.error;
+            detail.textContent = text;
+            detail.style.display = 'block';
+        } catch (error) {
+            this.showMessage('读取发送记录失败: ' + error.message, 'error');
+        }
+    }
+
+    async resendHistoryEntry(id) {
+        try {
+            const response = await fetch(`/api/send-history/${id}/resend`, { method: 'POST' });
+            const entry = await response.json();
+            if (response.ok) {
+                await this.loadSendHistory();
+                await this.showSendHistoryEntry(entry.id);
+            } else {
+                this.showMessage('重发失败: ' + entry.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('重发失败: ' + error.message, 'error');
+        }
+    }
+
+    async diffSendHistory() {
+        const a = document.getElementById('history-diff-a').value;
+        const b = document.getElementById('history-diff-b').value;
+        if (!a || !b) {
+            this.showMessage('请输入要对比的两条记录ID', 'error');
+            return;
+        }
+
+        try {
+            const response = await fetch(`/api/send-history/diff?a=${a}&b=${b}`);
+            const diff = await response.json();
+            if (!response.ok) {
+                this.showMessage('对比失败: ' + diff.error, 'error');
+                return;
+            }
+
+            const formatLines = lines => lines.length === 0 ? '  (相同)\n' : lines.map(l => l.op + ' ' + l.text).join('\n') + '\n';
+            let text = `对比 #${diff.a.id} 与 #${diff.b.id}
+状态码: ${diff.a.status} / ${diff.b.status}
 
 `;
-                displayText += "响应头:\n";
-                for (const [key, value] of Object.entries(result.headers)) {
-                    displayText += `${key}: ${value}
+            text += '请求数据:\n' + formatLines(diff.request);
+            text += '\n原始请求:\n' + formatLines(diff.raw_request);
+            text += '\n响应头:\n';
+            if (diff.headers.length === 0) {
+                text += '  (相同)\n';
+            }
+            diff.headers.forEach(h => {
+                text += `  ${h.name}: ${(h.a || []).join(', ')} => ${(h.b || []).join(', ')}
 `;
-                }
-                displayText += "\n响应体:\n";
+            });
+            text += '\n响应体:\n' + formatLines(diff.body);
+
+            const detail = document.getElementById('send-history-detail');
+            detail.textContent = text;
+            detail.style.display = 'block';
+        } catch (error) {
+            this.showMessage('对比失败: ' + error.message, 'error');
+        }
+    }
+
+    // 加载版本历史
+    async loadRevisions() {
+        const file = document.getElementById('revision-file').value.trim();
+        try {
+            const response = await fetch(`/api/revisions?file=${encodeURIComponent(file)}`);
+            const result = await response.json();
+            const container = document.getElementById('revisions');
+            container.innerHTML = '';
+
+            if (!result.revisions || result.revisions.length === 0) {
+                container.innerHTML = '<p>暂无版本记录</p>';
+                return;
+            }
+
+            const actions = { save: '保存', restore: '恢复', external: '外部修改' };
+            result.revisions.slice(0, 100).forEach(revision => {
+                const item = document.createElement('div');
+                item.className = 'log-item';
+                const time = new Date(revision.time).toLocaleString('zh-CN');
+                item.innerHTML = `
+                    <div class="log-header">
+                        <span>#${revision.id}</span>
+                        <span>${revision.file}</span>
+                        <span style="font-size: 11px; color: #666;">${time}</span>
+                        <span style="font-size: 11px; color: #666;">${actions[revision.action] || revision.action} / ${revision.size}字节</span>
+                        <button class="toggle-btn" onclick="tool.showRevision(${revision.id})">查看</button>
+                        <button class="toggle-btn" onclick="tool.diffRevision(${revision.id})">对比当前</button>
+                        <button class="toggle-btn" onclick="tool.restoreRevision(${revision.id})">恢复文件</button>
+                        <button class="toggle-btn" onclick="tool.restoreProjectRevision(${revision.id})">恢复项目</button>
+                    </div>
+                `;
+                container.appendChild(item);
+            });
+        } catch (error) {
+            console.error('加载版本历史失败:', error);
+        }
+    }
+
+    async showRevision(id) {
+        try {
+            const response = await fetch(`/api/revisions/${id}`);
+            const result = await response.json();
+            if (!response.ok) {
+                this.showMessage('读取版本失败: ' + result.error, 'error');
+                return;
+            }
+            const detail = document.getElementById('revision-detail');
+            detail.textContent = `#${result.revision.id}  ${result.revision.file}
+
+${result.content}`;
+            detail.style.display = 'block';
+        } catch (error) {
+            this.showMessage('读取版本失败: ' + error.message, 'error');
+        }
+    }
+
+    async diffRevision(id) {
+        try {
+            const response = await fetch(`/api/revisions/diff?from=${id}&to=current`);
+            const diff = await response.json();
+            if (!response.ok) {
+                this.showMessage('对比失败: ' + diff.error, 'error');
+                return;
+            }
+            const detail = document.getElementById('revision-detail');
+            detail.textContent = `对比 #${diff.from.id} 与当前 ${diff.file}
+
+` +
+                (diff.lines.length === 0 ? '  (相同)' : diff.lines.map(l => l.op + ' ' + l.text).join('\n'));
+            detail.style.display = 'block';
+        } catch (error) {
+            this.showMessage('对比失败: ' + error.message, 'error');
+        }
+    }
+
+    async restoreRevision(id) {
+        if (!confirm(`确定把文件恢复到版本 #${id}？当前内容会保留在版本历史中。`)) return;
+        await this.postRevisionRestore(`/api/revisions/${id}/restore`, {});
+    }
+
+    async restoreProjectRevision(id) {
+        if (!confirm(`确定把整个项目恢复到版本 #${id} 时的状态？之后新建的文件保持不变。`)) return;
+        await this.postRevisionRestore('/api/revisions/restore-project', { revision: id });
+    }
+
+    async postRevisionRestore(url, body) {
+        try {
+            const response = await fetch(url, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body)
+            });
+            const result = await response.json();
+            if (!response.ok) {
+                this.showMessage('恢复失败: ' + result.error, 'error');
+                return;
+            }
+            this.showMessage(result.message, 'success');
+            await this.loadRevisions();
+            await this.loadJSONFiles();
+        } catch (error) {
+            this.showMessage('恢复失败: ' + error.message, 'error');
+        }
+    }
+
+    // 将已保存的表单字段还原为JSON对象文本
+    formFieldsToText(fields) {
+        if (!fields || fields.length === 0) return '';
+        const obj = {};
+        fields.forEach(field => {
+            if (field.file) {
+                obj[field.key] = '@' + field.file;
+            } else if (field.content_type === 'application/json') {
                 try {
-                    const jsonBody = JSON.parse(result.body);
-                    displayText += JSON.stringify(jsonBody, null, 2);
+                    obj[field.key] = JSON.parse(field.value);
                 } catch {
-                    displayText += result.body;
+                    obj[field.key] = field.value;
                 }
-                responseElement.textContent = displayText;
             } else {
-                responseElement.textContent = '发送请求失败: ' + result.error;
+                obj[field.key] = field.value;
+            }
+        });
+        return JSON.stringify(obj, null, 2);
+    }
+
+    // 将JSON对象转换为表单字段，值以@开头表示项目文件，非字符串值按JSON发送
+    buildFormFields(data) {
+        if (!data.trim()) return [];
+        const obj = JSON.parse(data);
+        return Object.entries(obj).map(([key, value]) => {
+            if (typeof value === 'string') {
+                if (value.startsWith('@')) {
+                    return { key: key, file: value.substring(1) };
+                }
+                return { key: key, value: value };
+            }
+            return { key: key, value: JSON.stringify(value), content_type: 'application/json' };
+        });
+    }
+
+    // 启动压测
+    async startLoadTest(index) {
+        this.updateSendBlockConfig(index);
+        const block = this.sendBlocks[index];
+        const config = {
+            block: block,
+            data: document.getElementById(`send-data-${index}`).value,
+            concurrency: parseInt(document.getElementById(`lt-concurrency-${index}`).value) || 1,
+            total_requests: parseInt(document.getElementById(`lt-total-${index}`).value) || 0,
+            duration_seconds: parseInt(document.getElementById(`lt-duration-${index}`).value) || 0,
+            start_rps: parseFloat(document.getElementById(`lt-start-rps-${index}`).value) || 0,
+            target_rps: parseFloat(document.getElementById(`lt-target-rps-${index}`).value) || 0,
+            ramp_seconds: parseInt(document.getElementById(`lt-ramp-${index}`).value) || 0
+        };
+
+        try {
+            const response = await fetch('/api/loadtest/start', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(config)
+            });
+            const result = await response.json();
+            if (response.ok) {
+                this.loadTestIndex = index;
+                document.getElementById(`lt-result-${index}`).textContent = '压测已启动...';
+            } else {
+                alert('启动压测失败: ' + result.error);
             }
         } catch (error) {
-            document.getElementById(`send-response-${index}`).textContent = '发送请求失败: ' + error.message;
+            alert('启动压测失败: ' + error.message);
         }
+    }
 
-        // 保存当前配置
-        this.updateSendBlockConfig(index);
+    // 停止压测
+    async stopLoadTest() {
+        try {
+            const response = await fetch('/api/loadtest/stop', { method: 'POST' });
+            const result = await response.json();
+            if (!response.ok) {
+                alert('停止压测失败: ' + result.error);
+            }
+        } catch (error) {
+            alert('停止压测失败: ' + error.message);
+        }
+    }
+
+    // 显示压测进度
+    displayLoadTest(snapshot) {
+        if (this.loadTestIndex === undefined) return;
+        const resultElement = document.getElementById(`lt-result-${this.loadTestIndex}`);
+        if (!resultElement) return;
+
+        const latency = snapshot.latency || {};
+        let text = `${snapshot.running ? '运行中' : '已结束'}  耗时: ${snapshot.elapsed_seconds}s
+`;
+        text += `已发送: ${snapshot.sent}  已完成: ${snapshot.completed}  失败: ${snapshot.failed}
+`;
+        text += `目标RPS: ${snapshot.current_rps.toFixed(1)}  实际RPS: ${snapshot.actual_rps}
+
+`;
+        text += `延迟(ms): min ${latency.min_ms}  mean ${latency.mean_ms}  p50 ${latency.p50_ms}  p90 ${latency.p90_ms}  p99 ${latency.p99_ms}  max ${latency.max_ms}
+
+`;
+        text += '状态码:\n';
+        for (const [code, count] of Object.entries(snapshot.status_codes || {})) {
+            text += `  ${code}: ${count}
+`;
+        }
+        if (Object.keys(snapshot.errors || {}).length > 0) {
+            text += '错误类型:\n';
+            for (const [type, count] of Object.entries(snapshot.errors)) {
+                text += `  ${type}: ${count}
+`;
+            }
+        }
+        resultElement.textContent = text;
     }
 
     // 启用编辑模式
@@ -701,36 +1525,64 @@ class HTTPJSONTool {
     // 更新单个发送块配置
     updateSendBlockConfig(index) {
         if (index >= 0 && index < this.sendBlocks.length) {
-            this.sendBlocks[index] = {
-                name: document.getElementById(`send-name-${index}`).value,
-                url: document.getElementById(`send-url-${index}`).value,
-                send_file: document.getElementById(`send-file-${index}`).value,
-                method: document.getElementById(`send-method-${index}`).value,
-                headers: document.getElementById(`send-headers-${index}`).value
-            };
+            this.sendBlocks[index] = this.readSendBlockForm(index, this.sendBlocks[index]);
             this.saveSendBlocksConfig();
         }
     }
 
+    // 从页面读取发送块配置，保留页面上没有的其它字段
+    readSendBlockForm(index, block) {
+        const updated = {
+            ...block,
+            name: document.getElementById(`send-name-${index}`).value,
+            url: document.getElementById(`send-url-${index}`).value,
+            send_file: document.getElementById(`send-file-${index}`).value,
+            method: document.getElementById(`send-method-${index}`).value,
+            headers: document.getElementById(`send-headers-${index}`).value,
+            body_type: document.getElementById(`send-body-type-${index}`).value,
+            body_file: document.getElementById(`send-body-file-${index}`).value
+        };
+
+        const authText = document.getElementById(`send-auth-${index}`).value;
+        if (!authText.trim()) {
+            delete updated.auth;
+        } else {
+            try {
+                updated.auth = JSON.parse(authText);
+            } catch (error) {
+                // 格式错误时保留原有认证配置
+            }
+        }
+
+        const transportText = document.getElementById(`send-transport-${index}`).value;
+        if (!transportText.trim()) {
+            delete updated.transport;
+        } else {
+            try {
+                updated.transport = JSON.parse(transportText);
+            } catch (error) {
+                // 格式错误时保留原有传输配置
+            }
+        }
+
+        const data = document.getElementById(`send-data-${index}`).value;
+        if ((updated.body_type === 'form' || updated.body_type === 'multipart') && data.trim()) {
+            try {
+                updated.form_fields = this.buildFormFields(data);
+            } catch (error) {
+                // 数据不是合法JSON时保留原有字段
+            }
+        }
+        return updated;
+    }
+
     // 保存发送块配置到服务器
     async saveSendBlocksConfig() {
         // 收集所有发送块的当前配置
         const blocks = [];
         for (let i = 0; i < this.sendBlocks.length; i++) {
-            const nameElem = document.getElementById(`send-name-${i}`);
-            const urlElem = document.getElementById(`send-url-${i}`);
-            const fileElem = document.getElementById(`send-file-${i}`);
-            const methodElem = document.getElementById(`send-method-${i}`);
-            const headersElem = document.getElementById(`send-headers-${i}`);
-
-            if (nameElem && urlElem && fileElem && methodElem && headersElem) {
-                blocks.push({
-                    name: nameElem.value,
-                    url: urlElem.value,
-                    send_file: fileElem.value,
-                    method: methodElem.value,
-                    headers: headersElem.value
-                });
+            if (document.getElementById(`send-name-${i}`)) {
+                blocks.push(this.readSendBlockForm(i, this.sendBlocks[i]));
             }
         }
 
@@ -740,6 +1592,148 @@ class HTTPJSONTool {
         console.log('发送块配置已更新', this.sendBlocks);
     }
 
+    async loadWSMockConnections() {
+        try {
+            const response = await fetch('/api/ws-mock/connections');
+            const conns = await response.json();
+            const select = document.getElementById('ws-mock-target');
+            const current = select.value;
+            select.innerHTML = '<option value="0">全部连接</option>' + conns.map(conn =>
+                `<option value="${conn.id}">#${conn.id} ${conn.path} (${conn.remote_addr})</option>`
+            ).join('');
+            select.value = conns.some(conn => String(conn.id) === current) ? current : '0';
+        } catch (error) {
+            console.error('加载WebSocket连接失败:', error);
+        }
+    }
+
+    async pushWSMockMessage() {
+        const message = document.getElementById('ws-mock-message').value;
+        try {
+            const response = await fetch('/api/ws-mock/push', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    connection_id: parseInt(document.getElementById('ws-mock-target').value) || 0,
+                    message: message
+                })
+            });
+            const result = await response.json();
+            if (response.ok) {
+                this.showMessage(`已推送到 ${result.sent} 个连接`, 'success');
+            } else {
+                this.showMessage(result.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('推送失败: ' + error.message, 'error');
+        }
+    }
+
+    async loadStreamClients() {
+        try {
+            const response = await fetch('/api/stream-client');
+            const sessions = await response.json();
+            const select = document.getElementById('stream-session');
+            const current = select.value;
+            select.innerHTML = sessions.length === 0 ? '<option value="">无连接</option>' : sessions.map(session =>
+                `<option value="${session.id}">#${session.id} ${session.kind} ${session.url}</option>`
+            ).join('');
+            if (sessions.some(session => String(session.id) === current)) {
+                select.value = current;
+            }
+        } catch (error) {
+            console.error('加载流式连接失败:', error);
+        }
+
+        const fileSelect = document.getElementById('stream-file');
+        fileSelect.innerHTML = '<option value="">使用输入的消息</option>' +
+            this.jsonFiles.map(file => `<option value="${file}">${file}</option>`).join('');
+    }
+
+    async connectStreamClient() {
+        let headers = {};
+        const headersText = document.getElementById('stream-headers').value.trim();
+        if (headersText) {
+            try {
+                headers = JSON.parse(headersText);
+            } catch (e) {
+                this.showMessage('请求头格式错误: ' + e.message, 'error');
+                return;
+            }
+        }
+
+        try {
+            const response = await fetch('/api/stream-client', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ url: document.getElementById('stream-url').value.trim(), headers: headers })
+            });
+            const result = await response.json();
+            if (response.ok) {
+                await this.loadStreamClients();
+                document.getElementById('stream-session').value = String(result.id);
+                this.showMessage(`已连接 #${result.id}`, 'success');
+            } else {
+                this.showMessage(result.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('连接失败: ' + error.message, 'error');
+        }
+    }
+
+    async sendStreamMessage() {
+        const id = document.getElementById('stream-session').value;
+        if (!id) {
+            this.showMessage('请先建立连接', 'error');
+            return;
+        }
+        try {
+            const response = await fetch(`/api/stream-client/${id}/send`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    message: document.getElementById('stream-message').value,
+                    file: document.getElementById('stream-file').value
+                })
+            });
+            const result = await response.json();
+            if (!response.ok) {
+                this.showMessage(result.error, 'error');
+            }
+        } catch (error) {
+            this.showMessage('发送失败: ' + error.message, 'error');
+        }
+    }
+
+    async closeStreamClient() {
+        const id = document.getElementById('stream-session').value;
+        if (!id) {
+            return;
+        }
+        await fetch(`/api/stream-client/${id}`, { method: 'DELETE' });
+    }
+
+    handleStreamStatus(status) {
+        const text = status.status === 'connected' ? '已连接' : '已断开' + (status.error ? '：' + status.error : '');
+        this.addStreamFrame({ session_id: status.session_id, direction: 'status', data: text, timestamp: new Date().toISOString() });
+        this.loadStreamClients();
+    }
+
+    addStreamFrame(frame) {
+        const container = document.getElementById('stream-frames');
+        const line = document.createElement('div');
+        const time = new Date(frame.timestamp).toLocaleTimeString('zh-CN');
+        const arrow = frame.direction === 'in' ? '←' : (frame.direction === 'out' ? '→' : '·');
+        const meta = [frame.event && 'event=' + frame.event, frame.id && 'id=' + frame.id, frame.binary && '[二进制base64]'].filter(Boolean).join(' ');
+        // 帧内容使用textContent显示，避免被当作HTML解析
+        line.textContent = `${time} #${frame.session_id} ${arrow} ${meta ? meta + ' ' : ''}${frame.data}`;
+        line.style.whiteSpace = 'pre-wrap';
+        container.insertBefore(line, container.firstChild);
+        while (container.children.length > 500) {
+            container.removeChild(container.lastChild);
+        }
+    }
+
     async refreshLogs() {
         try {
             const response = await fetch('/api/logs');
@@ -772,6 +1766,10 @@ class HTTPJSONTool {
         const container = document.getElementById('request-logs');
         const logDiv = document.createElement('div');
         logDiv.className = 'log-item';
+        const validation = log.schema_validation;
+        if (validation && !validation.valid) {
+            logDiv.classList.add('schema-invalid');
+        }
 
         const timestamp = new Date(log.timestamp).toLocaleString('zh-CN', {
             month: '2-digit', day: '2-digit',
@@ -800,13 +1798,19 @@ class HTTPJSONTool {
             <div class="log-header">
                 <span class="log-method ${log.method}">${log.method}</span>
                 <span>${log.path}</span>
+                ${log.protocol ? `<span style="font-size: 11px; color: #666;">${log.protocol}</span>` : ''}
                 <span style="font-size: 11px; color: #666;">${timestamp}</span>
                 <span style="font-size: 11px; color: #666;">${Object.keys(log.headers).length}个头</span>
+                ${log.client_cert_subject ? `<span style="font-size: 11px; color: #666;">证书: ${log.client_cert_subject}</span>` : ''}
+                ${log.graphql ? `<span style="font-size: 11px; color: #e535ab;">${log.graphql.operation_type || 'query'} ${log.graphql.operation_name || '(匿名)'}</span>` : ''}
+                ${validation ? `<span style="font-size: 11px; color: ${validation.valid ? '#28a745' : '#dc3545'};">${validation.valid ? 'Schema通过' : 'Schema失败(' + (validation.errors || []).length + ')'}</span>` : ''}
                 <button class="toggle-btn" onclick="this.parentElement.nextElementSibling.style.display = this.parentElement.nextElementSibling.style.display === 'none' ? 'block' : 'none'">展开</button>
             </div>
             <div class="log-content" style="display: none;">
+                ${validation && !validation.valid ? `<div style="color: #dc3545;"><strong>Schema校验失败 (${validation.schema}):</strong></div><pre style="background: #fff5f5; padding: 8px; border-radius: 3px; margin: 5px 0; white-space: pre-wrap; font-family: monospace; font-size: 12px;">${(validation.errors || []).join('\n').replace(/</g, '&lt;')}</pre>` : ''}
                 <div><strong>请求体:</strong></div>
                 <pre style="background: #f5f5f5; padding: 8px; border-radius: 3px; margin: 5px 0; white-space: pre-wrap; font-family: monospace; font-size: 12px; max-height: 300px; overflow-y: auto;">${formattedFullBody || '(空)'}</pre>
+                ${log.graphql && log.graphql.variables ? `<div><strong>GraphQL变量:</strong></div><pre style="background: #f5f5f5; padding: 8px; border-radius: 3px; margin: 5px 0; white-space: pre-wrap; font-family: monospace; font-size: 12px;">${JSON.stringify(log.graphql.variables, null, 2)}</pre>` : ''}
                 ${Object.keys(log.headers).length > 0 ? `<div style="margin-top: 5px;"><strong>主要请求头:</strong></div><div>${Object.entries(log.headers).slice(0, 3).map(([k,v]) => `${k}: ${Array.isArray(v) ? v[0] : v}`).join('<br>')}</div>` : ''}
             </div>
         `;
@@ -828,14 +1832,16 @@ class HTTPJSONTool {
         try {
             const response = await fetch(`/api/read-json?file=${encodeURIComponent(filename)}`);
             if (response.ok) {
-                const jsonData = await response.json();
+                // JSON以外的文本文件原样编辑
+                const isJSON = filename.toLowerCase().endsWith('.json');
+                const content = isJSON ? JSON.stringify(await response.json(), null, 2) : await response.text();
                 const textarea = document.getElementById('json-content');
                 const editBtn = document.getElementById('edit-json');
                 const saveBtn = document.getElementById('save-json');
 
                 document.getElementById('json-file-name').textContent = `编辑: ${filename}`;
-                // 格式化JSON显示
-                textarea.value = JSON.stringify(jsonData, null, 2);
+                // JSON格式化显示，其他文本原样显示
+                textarea.value = content;
                 document.getElementById('json-editor').style.display = 'block';
                 this.currentEditingFile = filename;
 
@@ -891,12 +1897,14 @@ class HTTPJSONTool {
 
         const content = document.getElementById('json-content').value;
 
-        // 验证JSON格式
-        try {
-            JSON.parse(content);
-        } catch (error) {
-            this.showMessage('JSON格式错误: ' + error.message, 'error');
-            return;
+        // 验证JSON格式，XML由服务端校验
+        if (this.currentEditingFile.toLowerCase().endsWith('.json')) {
+            try {
+                JSON.parse(content);
+            } catch (error) {
+                this.showMessage('JSON格式错误: ' + error.message, 'error');
+                return;
+            }
         }
 
         try {
@@ -975,10 +1983,11 @@ class HTTPJSONTool {
             const select = document.getElementById('project-select');
             select.innerHTML = '';
 
-            projects.forEach(proj => {
+            this.projects = projects || [];
+            this.projects.forEach(proj => {
                 const option = document.createElement('option');
                 option.value = proj.name;
-                option.textContent = proj.name;
+                option.textContent = proj.archived ? proj.name + ' (已归档)' : proj.name;
                 // 设置当前项目为选中状态
                 if (proj.name === currentProject) {
                     option.selected = true;
@@ -996,6 +2005,21 @@ async function switchProject() {
     const select = document.getElementById('project-select');
     const projectName = select.value;
 
+    // 已归档的项目需要先取消归档
+    const project = (tool.projects || []).find(p => p.name === projectName);
+    if (project && project.archived) {
+        if (!confirm('项目 ' + projectName + ' 已归档，是否取消归档并切换？')) {
+            await tool.loadProjects();
+            return;
+        }
+        const response = await fetch('/api/projects/' + encodeURIComponent(projectName) + '/unarchive', { method: 'POST' });
+        if (!response.ok) {
+            const error = await response.json();
+            alert('取消归档失败: ' + error.error);
+            return;
+        }
+    }
+
     try {
         const response = await fetch('/api/switch-project', {
             method: 'POST',
@@ -1027,25 +2051,172 @@ async function createNewProject() {
     }
 
     try {
+        const templates = await (await fetch('/api/project-templates')).json();
+        const choices = ['留空：空白项目'];
+        templates.builtin.forEach(t => choices.push(t.name + '：' + t.title + '，' + t.description));
+        choices.push('project:项目名：以已有项目为模板（' + templates.projects.map(p => p.name).join(', ') + '）');
+        choices.push('upload：上传项目归档作为模板');
+        const choice = prompt('请选择项目模板:\n' + choices.join('\n'), '');
+        if (choice === null) return;
+
+        let template = null;
+        let defs = [];
+        if (choice.trim() === 'upload') {
+            defs = templates.common_variables;
+        } else if (choice.startsWith('project:')) {
+            const name = choice.substring('project:'.length).trim();
+            const project = templates.projects.find(p => p.name === name);
+            if (!project) {
+                alert('模板项目不存在: ' + name);
+                return;
+            }
+            template = { source: 'project', name: name };
+            defs = project.variables.map(v => templates.common_variables.find(c => c.name === v) || { name: v, description: v });
+        } else if (choice.trim() !== '') {
+            const builtin = templates.builtin.find(t => t.name === choice.trim());
+            if (!builtin) {
+                alert('内置模板不存在: ' + choice);
+                return;
+            }
+            template = { source: 'builtin', name: builtin.name };
+            defs = templates.common_variables.concat(builtin.variables);
+        }
+
+        // 逐个填写模板变量，留空使用默认值
+        const variables = {};
+        for (const def of defs) {
+            if (def.name === 'project') continue;
+            const value = prompt('模板变量 ' + def.name + '（' + def.description + '）:', def.default || '');
+            if (value === null) return;
+            if (value !== '' && value !== def.default) variables[def.name] = value;
+        }
+
+        if (choice.trim() === 'upload') {
+            const input = document.getElementById('project-template-upload');
+            input.dataset.name = projectName;
+            input.dataset.variables = JSON.stringify(variables);
+            input.click();
+            return;
+        }
+
+        const body = { name: projectName };
+        if (template) {
+            body.template = template;
+            body.variables = variables;
+        }
         const response = await fetch('/api/projects', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ name: projectName })
+            body: JSON.stringify(body)
         });
+        await finishCreateProject(response, projectName);
+    } catch (error) {
+        alert('项目创建失败: ' + error.message);
+    }
+}
 
-        if (response.ok) {
-            alert('项目创建成功！');
-            // 重新加载项目列表
-            await tool.loadProjects();
-            // 切换到新项目
-            document.getElementById('project-select').value = projectName;
-            await switchProject();
+// 全局函数：上传归档作为模板创建项目
+async function createProjectFromArchive(input) {
+    if (input.files.length === 0) return;
+    const form = new FormData();
+    form.append('file', input.files[0]);
+    form.append('name', input.dataset.name);
+    form.append('variables', input.dataset.variables || '{}');
+    input.value = '';
+
+    try {
+        const response = await fetch('/api/projects', { method: 'POST', body: form });
+        await finishCreateProject(response, input.dataset.name);
+    } catch (error) {
+        alert('项目创建失败: ' + error.message);
+    }
+}
+
+async function finishCreateProject(response, projectName) {
+    if (response.ok) {
+        alert('项目创建成功！');
+        // 重新加载项目列表
+        await tool.loadProjects();
+        // 切换到新项目
+        document.getElementById('project-select').value = projectName;
+        await switchProject();
+    } else {
+        const error = await response.json();
+        alert('项目创建失败: ' + error.error);
+    }
+}
+
+// 全局函数：项目管理操作，重命名和克隆针对当前项目，归档和删除需要输入项目名
+async function projectAction(select) {
+    const action = select.value;
+    select.value = '';
+    const current = document.getElementById('project-select').value;
+    const others = (tool.projects || []).filter(p => p.name !== current && !p.archived).map(p => p.name);
+
+    try {
+        let response;
+        if (action === 'rename' || action === 'clone') {
+            const newName = prompt(action === 'rename' ? '请输入新的项目名称:' : '请输入克隆项目的名称:', action === 'clone' ? current + '-copy' : current);
+            if (!newName || newName === current) return;
+            response = await fetch('/api/projects/' + encodeURIComponent(current) + '/' + action, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ new_name: newName })
+            });
+        } else if (action === 'archive') {
+            const name = prompt('请输入要归档的项目名称（当前项目不能归档）:\n' + others.join(', '));
+            if (!name) return;
+            response = await fetch('/api/projects/' + encodeURIComponent(name) + '/archive', { method: 'POST' });
+        } else if (action === 'delete') {
+            const name = prompt('请输入要删除的项目名称（当前项目不能删除）:\n' + (tool.projects || []).filter(p => p.name !== current).map(p => p.name).join(', '));
+            if (!name) return;
+            const url = '/api/projects/' + encodeURIComponent(name);
+            response = await fetch(url, { method: 'DELETE' });
+            if (response.status === 409) {
+                const pending = await response.json();
+                if (!confirm('确定删除项目 ' + name + '（共' + pending.files + '个文件）？删除后无法恢复。')) return;
+                response = await fetch(url + '?confirm_token=' + pending.confirm_token, { method: 'DELETE' });
+            }
+        } else if (action === 'export') {
+            window.location.href = '/api/projects/' + encodeURIComponent(current) + '/export';
+            return;
+        } else if (action === 'import') {
+            document.getElementById('project-import').click();
+            return;
         } else {
-            const error = await response.json();
-            alert('项目创建失败: ' + error.error);
+            return;
+        }
+
+        const result = await response.json();
+        if (!response.ok) {
+            alert('操作失败: ' + result.error);
+            return;
         }
+        tool.showMessage(result.message, 'success');
+        await tool.loadProjects();
     } catch (error) {
-        alert('项目创建失败: ' + error.message);
+        alert('操作失败: ' + error.message);
+    }
+}
+
+// 全局函数：导入项目归档，同名项目自动改名
+async function importProject(input) {
+    if (input.files.length === 0) return;
+    const form = new FormData();
+    form.append('file', input.files[0]);
+    input.value = '';
+
+    try {
+        const response = await fetch('/api/projects/import', { method: 'POST', body: form });
+        const result = await response.json();
+        if (!response.ok) {
+            alert('导入失败: ' + result.error);
+            return;
+        }
+        tool.showMessage('项目已导入为 ' + result.name, 'success');
+        await tool.loadProjects();
+    } catch (error) {
+        alert('导入失败: ' + error.message);
     }
 }
 
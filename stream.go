@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const endpointTypeStream = "stream"
+
+// 流式响应格式
+const (
+	streamFormatSSE     = "sse"
+	streamFormatNDJSON  = "ndjson"
+	streamFormatChunked = "chunked"
+)
+
+// 流式响应模式：finite发送一遍后结束，loop循环发送直到客户端断开
+const (
+	streamModeFinite = "finite"
+	streamModeLoop   = "loop"
+)
+
+const defaultStreamInterval = 1000
+
+// 流式响应配置
+type StreamConfig struct {
+	Format      string        `json:"format"`
+	Mode        string        `json:"mode,omitempty"`
+	IntervalMs  int           `json:"interval_ms,omitempty"`
+	ContentType string        `json:"content_type,omitempty"`
+	RetryMs     int           `json:"retry_ms,omitempty"`
+	Events      []StreamEvent `json:"events"`
+}
+
+// 流中的一个事件或数据块。Data与File二选一，File为JSON文件目录下的文件；
+// DelayMs大于0时替代该事件之前的间隔
+type StreamEvent struct {
+	ID      string `json:"id,omitempty"`
+	Event   string `json:"event,omitempty"`
+	Data    string `json:"data,omitempty"`
+	File    string `json:"file,omitempty"`
+	DelayMs int    `json:"delay_ms,omitempty"`
+}
+
+// 读取事件内容，{seq}替换为事件序号，{timestamp}替换为毫秒时间戳
+func (e StreamEvent) payload(project string, seq int) ([]byte, error) {
+	data := []byte(e.Data)
+	if e.File != "" {
+		fileData, err := readProjectFile(project, e.File)
+		if err != nil {
+			return nil, err
+		}
+		data = fileData
+	}
+	return []byte(strings.NewReplacer(
+		"{seq}", strconv.Itoa(seq),
+		"{timestamp}", strconv.FormatInt(time.Now().UnixMilli(), 10),
+	).Replace(string(data))), nil
+}
+
+// 合法JSON压缩为单行，SSE和NDJSON都按行分隔
+func compactJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if json.Compact(&buf, data) == nil {
+		return buf.Bytes()
+	}
+	return data
+}
+
+func writeSSEEvent(w gin.ResponseWriter, event StreamEvent, seq int, data []byte) error {
+	var buf bytes.Buffer
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", strings.ReplaceAll(event.ID, "{seq}", strconv.Itoa(seq)))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event.Event)
+	}
+	for _, line := range strings.Split(string(compactJSON(data)), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// 流式响应接口，客户端断开时立即结束
+func handleStreamEndpoint(c *gin.Context, path string, config *StreamConfig) {
+	recordIncomingRequest(c, path)
+
+	if config == nil || len(config.Events) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "流式响应未配置事件"})
+		return
+	}
+
+	contentType := config.ContentType
+	switch config.Format {
+	case streamFormatSSE:
+		if contentType == "" {
+			contentType = "text/event-stream; charset=utf-8"
+		}
+	case streamFormatNDJSON:
+		if contentType == "" {
+			contentType = "application/x-ndjson"
+		}
+	case streamFormatChunked:
+		if contentType == "" {
+			contentType = "text/plain; charset=utf-8"
+		}
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "不支持的流式响应格式: " + config.Format})
+		return
+	}
+
+	interval := config.IntervalMs
+	if interval <= 0 {
+		interval = defaultStreamInterval
+	}
+
+	project := currentProject
+	w := c.Writer
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// 避免反向代理缓冲
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if config.Format == streamFormatSSE && config.RetryMs > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", config.RetryMs)
+	}
+	w.Flush()
+
+	done := c.Request.Context().Done()
+	seq := 0
+	for {
+		for i, event := range config.Events {
+			delay := event.DelayMs
+			if delay <= 0 && seq > 0 {
+				delay = interval
+			}
+			if delay > 0 {
+				select {
+				case <-time.After(time.Duration(delay) * time.Millisecond):
+				case <-done:
+					return
+				}
+			}
+
+			seq++
+			data, err := event.payload(project, seq)
+			if err != nil {
+				data = []byte(fmt.Sprintf("读取事件 %d 失败: %v", i+1, err))
+			}
+
+			switch config.Format {
+			case streamFormatSSE:
+				err = writeSSEEvent(w, event, seq, data)
+			case streamFormatNDJSON:
+				_, err = w.Write(append(compactJSON(data), '\n'))
+			default:
+				_, err = w.Write(data)
+			}
+			if err != nil {
+				return
+			}
+			w.Flush()
+		}
+
+		if config.Mode != streamModeLoop {
+			return
+		}
+	}
+}
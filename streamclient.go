@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 流式客户端连接类型
+const (
+	streamClientWebSocket = "websocket"
+	streamClientSSE       = "sse"
+)
+
+// 建立流式连接的参数，ws/wss地址使用WebSocket，http/https地址按SSE订阅
+type StreamClientRequest struct {
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	Auth      *AuthConfig       `json:"auth"`
+	Transport *TransportConfig  `json:"transport"`
+}
+
+// 收到或发出的一帧，通过/ws转发给页面
+type StreamClientFrame struct {
+	SessionID int       `json:"session_id"`
+	Direction string    `json:"direction"`
+	ID        string    `json:"id,omitempty"`
+	Event     string    `json:"event,omitempty"`
+	Data      string    `json:"data"`
+	Binary    bool      `json:"binary,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type streamClientSession struct {
+	ID          int       `json:"id"`
+	Kind        string    `json:"kind"`
+	URL         string    `json:"url"`
+	Project     string    `json:"project"`
+	ConnectedAt time.Time `json:"connected_at"`
+
+	// ws在连接建立后才设置，读写需持有mu
+	mu      sync.Mutex
+	ws      *websocket.Conn
+	cancel  context.CancelFunc
+	writeMu sync.Mutex
+}
+
+type streamClientRegistry struct {
+	mu       sync.Mutex
+	nextID   int
+	sessions map[int]*streamClientSession
+}
+
+var streamClients = &streamClientRegistry{sessions: make(map[int]*streamClientSession)}
+
+func (r *streamClientRegistry) add(session *streamClientSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	session.ID = r.nextID
+	r.sessions[session.ID] = session
+}
+
+func (r *streamClientRegistry) get(id int) *streamClientSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions[id]
+}
+
+func (r *streamClientRegistry) remove(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+}
+
+func (r *streamClientRegistry) list() []*streamClientSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*streamClientSession, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		result = append(result, session)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (s *streamClientSession) relay(frame StreamClientFrame) {
+	frame.SessionID = s.ID
+	frame.Timestamp = time.Now()
+	broadcastToClients(map[string]interface{}{"type": "stream_client_frame", "data": frame})
+}
+
+func (s *streamClientSession) closed(err error) {
+	streamClients.remove(s.ID)
+	status := map[string]interface{}{"session_id": s.ID, "status": "closed"}
+	if err != nil {
+		status["error"] = err.Error()
+	}
+	broadcastToClients(map[string]interface{}{"type": "stream_client_status", "data": status})
+}
+
+func (s *streamClientSession) close() {
+	s.cancel()
+	s.mu.Lock()
+	ws := s.ws
+	s.mu.Unlock()
+	if ws != nil {
+		s.writeMu.Lock()
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		s.writeMu.Unlock()
+		ws.Close()
+	}
+}
+
+// 构造握手请求，复用发送请求的请求头和认证配置
+func buildStreamHandshake(client *http.Client, req StreamClientRequest, target string) (*http.Request, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	if req.Auth != nil && req.Auth.Type == authTypeHMAC {
+		return nil, errors.New("流式连接不支持HMAC签名认证")
+	}
+	if err := applyRequestAuth(client, httpReq, req.Auth, nil); err != nil {
+		return nil, err
+	}
+	return httpReq, nil
+}
+
+func (s *streamClientSession) dialWebSocket(ctx context.Context, client *http.Client, req StreamClientRequest) error {
+	httpReq, err := buildStreamHandshake(client, req, req.URL)
+	if err != nil {
+		return err
+	}
+
+	// 代理和TLS配置与发送请求一致
+	dialer := &websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: http.ProxyFromEnvironment}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+	// 由Dial补充的握手头不能重复设置
+	header := httpReq.Header.Clone()
+	for _, h := range []string{"Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions"} {
+		header.Del(h)
+	}
+
+	ws, resp, err := dialer.DialContext(ctx, httpReq.URL.String(), header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("WebSocket握手失败: %v (状态码 %d)", err, resp.StatusCode)
+		}
+		return fmt.Errorf("WebSocket连接失败: %v", err)
+	}
+	s.mu.Lock()
+	s.ws = ws
+	s.mu.Unlock()
+
+	go func() {
+		for {
+			messageType, data, err := ws.ReadMessage()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) || ctx.Err() != nil {
+					err = nil
+				}
+				s.closed(err)
+				return
+			}
+			// 二进制帧以base64转发，JSON编码会把非UTF-8字节替换掉
+			frame := StreamClientFrame{Direction: "in", Data: string(data)}
+			if messageType == websocket.BinaryMessage {
+				frame.Data, frame.Binary = base64.StdEncoding.EncodeToString(data), true
+			}
+			s.relay(frame)
+		}
+	}()
+	return nil
+}
+
+func (s *streamClientSession) dialSSE(ctx context.Context, client *http.Client, req StreamClientRequest) error {
+	httpReq, err := buildStreamHandshake(client, req, req.URL)
+	if err != nil {
+		return err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-cache")
+
+	// 长连接不设置整体超时，由关闭操作取消
+	streaming := *client
+	streaming.Timeout = 0
+	resp, err := streaming.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("SSE连接失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return fmt.Errorf("SSE连接失败: 状态码 %d", resp.StatusCode)
+	}
+
+	go func() {
+		defer resp.Body.Close()
+		err := readSSE(resp, func(frame StreamClientFrame) {
+			frame.Direction = "in"
+			s.relay(frame)
+		})
+		if ctx.Err() != nil {
+			err = nil
+		}
+		s.closed(err)
+	}()
+	return nil
+}
+
+// 按SSE规范解析事件，空行分隔，多行data以换行拼接
+func readSSE(resp *http.Response, emit func(StreamClientFrame)) error {
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var frame StreamClientFrame
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				frame.Data = strings.Join(data, "\n")
+				emit(frame)
+			}
+			frame = StreamClientFrame{ID: frame.ID}
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			frame.ID = value
+		case "event":
+			frame.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
+
+// API: 建立WebSocket或SSE客户端连接
+func connectStreamClient(c *gin.Context) {
+	var req StreamClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "地址格式错误: " + err.Error()})
+		return
+	}
+	var kind string
+	switch u.Scheme {
+	case "ws", "wss":
+		kind = streamClientWebSocket
+	case "http", "https":
+		kind = streamClientSSE
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "仅支持ws、wss、http、https地址"})
+		return
+	}
+
+	project := currentProject
+	client, err := buildHTTPClient(project, effectiveTransport(project, req.Transport), 2)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &streamClientSession{
+		Kind:        kind,
+		URL:         req.URL,
+		Project:     project,
+		ConnectedAt: time.Now(),
+		cancel:      cancel,
+	}
+	// 先登记以便读取协程使用会话ID
+	streamClients.add(session)
+
+	if kind == streamClientWebSocket {
+		err = session.dialWebSocket(ctx, client, req)
+	} else {
+		err = session.dialSSE(ctx, client, req)
+	}
+	if err != nil {
+		cancel()
+		streamClients.remove(session.ID)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("流式客户端 #%d 已连接: %s", session.ID, req.URL)
+	broadcastToClients(map[string]interface{}{
+		"type": "stream_client_status",
+		"data": map[string]interface{}{"session_id": session.ID, "status": "connected", "session": session},
+	})
+	c.JSON(http.StatusOK, session)
+}
+
+// API: 通过WebSocket客户端连接发送消息，file为JSON文件目录下的文件
+func sendStreamClientMessage(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	session := streamClients.get(id)
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "连接不存在"})
+		return
+	}
+	if session.Kind != streamClientWebSocket {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "SSE连接不支持发送消息"})
+		return
+	}
+	session.mu.Lock()
+	ws := session.ws
+	session.mu.Unlock()
+	if ws == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "连接尚未建立"})
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+		File    string `json:"file"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := []byte(req.Message)
+	if req.File != "" {
+		fileData, err := readProjectFile(session.Project, req.File)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败: " + err.Error()})
+			return
+		}
+		data = fileData
+	}
+
+	session.writeMu.Lock()
+	ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	err := ws.WriteMessage(websocket.TextMessage, data)
+	session.writeMu.Unlock()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "发送失败: " + err.Error()})
+		return
+	}
+
+	session.relay(StreamClientFrame{Direction: "out", Data: string(data)})
+	c.JSON(http.StatusOK, gin.H{"message": "发送成功"})
+}
+
+// API: 关闭流式客户端连接
+func closeStreamClient(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	session := streamClients.get(id)
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "连接不存在"})
+		return
+	}
+	session.close()
+	c.JSON(http.StatusOK, gin.H{"message": "连接已关闭"})
+}
+
+// API: 列出流式客户端连接
+func listStreamClients(c *gin.Context) {
+	c.JSON(http.StatusOK, streamClients.list())
+}
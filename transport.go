@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// 发送请求的传输层配置，可以设置在发送块上，也可以作为项目默认配置
+type TransportConfig struct {
+	TimeoutSeconds     int    `json:"timeout_seconds,omitempty"`
+	CACertFile         string `json:"ca_cert_file,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	ClientCertFile     string `json:"client_cert_file,omitempty"`
+	ClientKeyFile      string `json:"client_key_file,omitempty"`
+	ServerName         string `json:"server_name,omitempty"`
+	ProxyURL           string `json:"proxy_url,omitempty"`
+	DisableRedirects   bool   `json:"disable_redirects,omitempty"`
+	MaxRedirects       int    `json:"max_redirects,omitempty"`
+	DisableHTTP2       bool   `json:"disable_http2,omitempty"`
+}
+
+const defaultSendTimeout = 30 * time.Second
+
+// 发送块未配置时使用项目的默认传输配置
+func effectiveTransport(project string, cfg *TransportConfig) *TransportConfig {
+	if cfg != nil {
+		return cfg
+	}
+	if project == currentProject {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return server.SendTransport
+	}
+
+	// 非当前项目从其配置文件读取
+	data, err := os.ReadFile(getConfigPath(project))
+	if err != nil {
+		return nil
+	}
+	var config Config
+	if json.Unmarshal(data, &config) != nil {
+		return nil
+	}
+	return config.SendTransport
+}
+
+// 缓存的Transport数量上限，超过时关闭全部空闲连接后重新缓存
+const maxCachedTransports = 32
+
+// 相同配置的发送共用Transport，复用keep-alive连接；每次新建Transport会留下空闲连接直到超时
+var (
+	transportCache   = make(map[string]*http.Transport)
+	transportCacheMu sync.Mutex
+)
+
+// 缓存键包含证书文件的修改时间，证书更新后重新创建Transport
+func transportCacheKey(project string, cfg *TransportConfig, maxConns int) string {
+	data, _ := json.Marshal(cfg)
+	key := fmt.Sprintf("%s|%d|%s", project, maxConns, data)
+	if cfg != nil {
+		for _, name := range []string{cfg.CACertFile, cfg.ClientCertFile, cfg.ClientKeyFile} {
+			if name == "" {
+				continue
+			}
+			if path, err := resolveProjectFile(project, name); err == nil {
+				if info, err := os.Stat(path); err == nil {
+					key += fmt.Sprintf("|%d", info.ModTime().UnixNano())
+				}
+			}
+		}
+	}
+	return key
+}
+
+// 根据传输配置创建HTTP客户端，证书文件取自项目文件目录
+func buildHTTPClient(project string, cfg *TransportConfig, maxConns int) (*http.Client, error) {
+	transport, err := cachedTransport(project, cfg, maxConns)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: defaultSendTimeout, Transport: transport}
+	if cfg == nil {
+		return client, nil
+	}
+
+	if cfg.TimeoutSeconds > 0 {
+		client.Timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	switch {
+	case cfg.DisableRedirects:
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	case cfg.MaxRedirects > 0:
+		maxRedirects := cfg.MaxRedirects
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("重定向次数超过 %d 次", maxRedirects)
+			}
+			return nil
+		}
+	}
+
+	return client, nil
+}
+
+func cachedTransport(project string, cfg *TransportConfig, maxConns int) (*http.Transport, error) {
+	key := transportCacheKey(project, cfg, maxConns)
+	transportCacheMu.Lock()
+	defer transportCacheMu.Unlock()
+	if transport, ok := transportCache[key]; ok {
+		return transport, nil
+	}
+
+	transport, err := buildTransport(project, cfg, maxConns)
+	if err != nil {
+		return nil, err
+	}
+	if len(transportCache) >= maxCachedTransports {
+		// 正在进行的请求不受影响，只关闭空闲连接
+		for k, old := range transportCache {
+			old.CloseIdleConnections()
+			delete(transportCache, k)
+		}
+	}
+	transportCache[key] = transport
+	return transport, nil
+}
+
+func buildTransport(project string, cfg *TransportConfig, maxConns int) (*http.Transport, error) {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: maxConns,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		ForceAttemptHTTP2:   true,
+	}
+	if cfg == nil {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		ServerName:         cfg.ServerName,
+	}
+	if cfg.CACertFile != "" {
+		path, err := resolveProjectFile(project, cfg.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		// 在系统证书的基础上追加自定义CA
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA证书格式错误")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		certPath, err := resolveProjectFile(project, cfg.ClientCertFile)
+		if err != nil {
+			return nil, err
+		}
+		keyPath, err := resolveProjectFile(project, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	// 支持http、https和socks5代理
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("代理地址格式错误: %v", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("不支持的代理协议: %s", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
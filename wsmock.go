@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/base64"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 接口类型，为空时是普通HTTP接口
+const endpointTypeWebSocket = "websocket"
+
+// 匹配方式
+const (
+	wsMatchContains = "contains"
+	wsMatchExact    = "exact"
+	wsMatchRegex    = "regex"
+)
+
+// WebSocket接口的脚本配置
+type WSMockConfig struct {
+	OnConnect []WSMessage  `json:"on_connect,omitempty"`
+	Rules     []WSRule     `json:"rules,omitempty"`
+	Periodic  []WSPeriodic `json:"periodic,omitempty"`
+}
+
+// 要发送的消息，File为JSON文件目录下的文件，Message中的{message}替换为收到的消息
+type WSMessage struct {
+	Message string `json:"message,omitempty"`
+	File    string `json:"file,omitempty"`
+	DelayMs int    `json:"delay_ms,omitempty"`
+}
+
+// 收到匹配的消息时回复，按顺序取第一条匹配的规则
+type WSRule struct {
+	Match     string      `json:"match"`
+	MatchType string      `json:"match_type,omitempty"`
+	Replies   []WSMessage `json:"replies"`
+}
+
+// 连接期间定时推送
+type WSPeriodic struct {
+	IntervalMs int `json:"interval_ms"`
+	WSMessage
+}
+
+// 模拟WebSocket接口上的一个客户端连接
+type wsMockConn struct {
+	ID          int       `json:"id"`
+	Path        string    `json:"path"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	done    chan struct{}
+}
+
+type wsMockRegistry struct {
+	mu     sync.Mutex
+	nextID int
+	conns  map[int]*wsMockConn
+}
+
+var wsMocks = &wsMockRegistry{conns: make(map[int]*wsMockConn)}
+
+func (r *wsMockRegistry) add(conn *wsMockConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	conn.ID = r.nextID
+	r.conns[conn.ID] = conn
+}
+
+func (r *wsMockRegistry) remove(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conns, id)
+}
+
+// 按连接ID或路径查找连接，两者都为空时返回全部连接
+func (r *wsMockRegistry) find(id int, path string) []*wsMockConn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*wsMockConn
+	for _, conn := range r.conns {
+		if (id == 0 || conn.ID == id) && (path == "" || conn.Path == path) {
+			result = append(result, conn)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (r *wsMockRegistry) closeAll() {
+	for _, conn := range r.find(0, "") {
+		conn.close(websocket.CloseGoingAway, "服务器已停止")
+	}
+}
+
+func (c *wsMockConn) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *wsMockConn) close(code int, reason string) {
+	c.writeMu.Lock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	c.conn.Close()
+}
+
+// 读取消息内容，文件优先于文本
+func (m WSMessage) render(project, received string) ([]byte, error) {
+	if m.File != "" {
+		return readProjectFile(project, m.File)
+	}
+	return []byte(strings.ReplaceAll(m.Message, "{message}", received)), nil
+}
+
+// 发送消息，设置了延迟时在连接关闭前等待
+func (c *wsMockConn) send(project string, m WSMessage, received string) {
+	if m.DelayMs > 0 {
+		select {
+		case <-time.After(time.Duration(m.DelayMs) * time.Millisecond):
+		case <-c.done:
+			return
+		}
+	}
+	data, err := m.render(project, received)
+	if err != nil {
+		log.Printf("WebSocket模拟消息读取失败: %v", err)
+		return
+	}
+	if err := c.write(websocket.TextMessage, data); err != nil {
+		log.Printf("WebSocket模拟消息发送失败: %v", err)
+	}
+}
+
+type compiledWSRule struct {
+	WSRule
+	re *regexp.Regexp
+}
+
+func compileWSRules(rules []WSRule) []compiledWSRule {
+	compiled := make([]compiledWSRule, 0, len(rules))
+	for _, rule := range rules {
+		item := compiledWSRule{WSRule: rule}
+		if rule.MatchType == wsMatchRegex {
+			re, err := regexp.Compile(rule.Match)
+			if err != nil {
+				log.Printf("WebSocket匹配规则正则错误 %q: %v", rule.Match, err)
+				continue
+			}
+			item.re = re
+		}
+		compiled = append(compiled, item)
+	}
+	return compiled
+}
+
+func (r compiledWSRule) matches(message string) bool {
+	switch r.MatchType {
+	case wsMatchExact:
+		return message == r.Match
+	case wsMatchRegex:
+		return r.re.MatchString(message)
+	default:
+		return strings.Contains(message, r.Match)
+	}
+}
+
+func logWSFrame(path, method, body string, headers map[string]interface{}) {
+	appendRequestLog(RequestLog{
+		ID:        len(server.RequestLogs) + 1,
+		Path:      path,
+		Method:    method,
+		Headers:   headers,
+		Body:      body,
+		Timestamp: time.Now(),
+		Protocol:  "websocket",
+	})
+}
+
+func broadcastWSMockEvent(event string, conn *wsMockConn) {
+	broadcastToClients(map[string]interface{}{
+		"type": "ws_mock_connection",
+		"data": map[string]interface{}{"event": event, "connection": conn},
+	})
+}
+
+// 模拟WebSocket接口：升级连接、按脚本回复并记录收到的每一帧
+func handleWSMockEndpoint(c *gin.Context, path string, config *WSMockConfig) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "该接口仅支持WebSocket连接"})
+		return
+	}
+	if config == nil {
+		config = &WSMockConfig{}
+	}
+
+	ws, err := server.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("WebSocket模拟接口升级失败: %v", err)
+		return
+	}
+
+	project := currentProject
+	conn := &wsMockConn{
+		Path:        path,
+		RemoteAddr:  c.Request.RemoteAddr,
+		ConnectedAt: time.Now(),
+		conn:        ws,
+		done:        make(chan struct{}),
+	}
+	wsMocks.add(conn)
+
+	headers := make(map[string]interface{})
+	for k, v := range c.Request.Header {
+		headers[k] = v
+	}
+	logWSFrame(path, "WS-CONNECT", "", headers)
+	broadcastWSMockEvent("open", conn)
+
+	defer func() {
+		close(conn.done)
+		wsMocks.remove(conn.ID)
+		ws.Close()
+		logWSFrame(path, "WS-CLOSE", "", map[string]interface{}{})
+		broadcastWSMockEvent("close", conn)
+	}()
+
+	go func() {
+		for _, m := range config.OnConnect {
+			conn.send(project, m, "")
+		}
+	}()
+
+	for _, periodic := range config.Periodic {
+		if periodic.IntervalMs <= 0 {
+			continue
+		}
+		go func(p WSPeriodic) {
+			ticker := time.NewTicker(time.Duration(p.IntervalMs) * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					conn.send(project, p.WSMessage, "")
+				case <-conn.done:
+					return
+				}
+			}
+		}(periodic)
+	}
+
+	rules := compileWSRules(config.Rules)
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// 二进制帧以base64记录，不参与规则匹配
+		if messageType == websocket.BinaryMessage {
+			logWSFrame(path, "WS-BINARY", base64.StdEncoding.EncodeToString(data), map[string]interface{}{})
+			continue
+		}
+
+		message := string(data)
+		logWSFrame(path, "WS", message, map[string]interface{}{})
+		for _, rule := range rules {
+			if rule.matches(message) {
+				go func(replies []WSMessage) {
+					for _, m := range replies {
+						conn.send(project, m, message)
+					}
+				}(rule.Replies)
+				break
+			}
+		}
+	}
+}
+
+// API: 列出模拟WebSocket接口上的连接
+func listWSMockConnections(c *gin.Context) {
+	conns := wsMocks.find(0, c.Query("path"))
+	if conns == nil {
+		conns = []*wsMockConn{}
+	}
+	c.JSON(http.StatusOK, conns)
+}
+
+// API: 向模拟WebSocket接口的连接手动推送消息
+func pushWSMockMessage(c *gin.Context) {
+	var req struct {
+		ConnectionID int    `json:"connection_id"`
+		Path         string `json:"path"`
+		Message      string `json:"message"`
+		File         string `json:"file"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := WSMessage{Message: req.Message, File: req.File}.render(currentProject, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取消息失败: " + err.Error()})
+		return
+	}
+
+	conns := wsMocks.find(req.ConnectionID, req.Path)
+	if len(conns) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "没有匹配的WebSocket连接"})
+		return
+	}
+
+	sent := 0
+	for _, conn := range conns {
+		if err := conn.write(websocket.TextMessage, data); err != nil {
+			log.Printf("WebSocket推送失败: %v", err)
+			continue
+		}
+		sent++
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "推送完成", "sent": sent})
+}